@@ -0,0 +1,235 @@
+// Command simulate plays crash rounds offline with the same crash point,
+// curve and cashout rules as the live game, and reports the empirical RTP of
+// a set of betting strategies as JSON or CSV.
+//
+//	go run ./cmd/simulate -rounds 5000000 -house-edge 1 -strategy auto:2 -strategy fixed:2@0.2 -strategy random:1.1-10
+package main
+
+import (
+	"casino_api_go/curve"
+	"casino_api_go/fairness"
+	"casino_api_go/games/crash"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type strategyFlags []crash.Strategy
+
+func (s *strategyFlags) String() string {
+	names := make([]string, len(*s))
+	for i, strategy := range *s {
+		names[i] = strategy.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+func (s *strategyFlags) Set(value string) error {
+	strategy, err := parseStrategy(value)
+	if err != nil {
+		return err
+	}
+	*s = append(*s, strategy)
+	return nil
+}
+
+// parseStrategy reads kind:target[@delay] or random:min-max[@delay], for
+// example auto:2, fixed:1.5@0.25 or random:1.1-10.
+func parseStrategy(spec string) (crash.Strategy, error) {
+	strategy := crash.Strategy{Bet: 1}
+
+	kind, rest, ok := strings.Cut(spec, ":")
+	if !ok {
+		return strategy, fmt.Errorf("strategy %q must look like kind:target", spec)
+	}
+	strategy.Kind = kind
+
+	rest, delay, hasDelay := strings.Cut(rest, "@")
+	if hasDelay {
+		value, err := strconv.ParseFloat(delay, 64)
+		if err != nil {
+			return strategy, fmt.Errorf("invalid delay in %q", spec)
+		}
+		strategy.Delay = value
+	}
+
+	if kind == crash.StrategyRandom {
+		low, high, ok := strings.Cut(rest, "-")
+		min, minErr := strconv.ParseFloat(low, 64)
+		max, maxErr := strconv.ParseFloat(high, 64)
+		if !ok || minErr != nil || maxErr != nil {
+			return strategy, fmt.Errorf("invalid range in %q", spec)
+		}
+		strategy.Min, strategy.Max = min, max
+	} else {
+		target, err := strconv.ParseFloat(rest, 64)
+		if err != nil {
+			return strategy, fmt.Errorf("invalid target in %q", spec)
+		}
+		strategy.Target = target
+	}
+
+	return strategy, strategy.Validate()
+}
+
+func main() {
+	rounds := flag.Int("rounds", 1000000, "number of rounds to play")
+	seed := flag.String("seed", "", "server seed for the crash points (random when empty)")
+	format := flag.String("format", "json", "output format: json or csv")
+	out := flag.String("out", "", "output file (stdout when empty)")
+	bet := flag.Float64("bet", 1, "bet amount of every strategy")
+
+	maxMultiplier := flag.Float64("max-multiplier", 100, "max multiplier")
+	houseEdge := flag.Float64("house-edge", 1, "house edge in percent")
+	instantCrash := flag.Float64("instant-crash", 0, "instant crash chance in percent")
+	distribution := flag.String("distribution", fairness.DistributionStandard, "crash point distribution: standard or pareto")
+	shape := flag.Float64("shape", 2, "pareto tail index")
+	curveKind := flag.String("curve", curve.Linear, "multiplier curve: linear, exponential or piecewise")
+	speed := flag.Float64("speed", 0.1, "multiplier speed of linear and exponential curves")
+	curvePoints := flag.String("curve-points", "", `piecewise curve points as JSON, e.g. [{"time":0,"multiplier":1},{"time":10,"multiplier":2}]`)
+	maxWin := flag.Float64("max-win", 0, "max win per bet (0 for no cap)")
+
+	var strategies strategyFlags
+	flag.Var(&strategies, "strategy", "strategy as kind:target[@delay] or random:min-max[@delay], repeatable (auto, fixed, random)")
+	flag.Parse()
+
+	if *rounds <= 0 {
+		log.Fatal("rounds must be positive")
+	}
+	if *bet <= 0 {
+		log.Fatal("bet must be positive")
+	}
+	if *format != "json" && *format != "csv" {
+		log.Fatalf("Unknown format %q", *format)
+	}
+
+	points, err := curve.ParsePoints(*curvePoints)
+	if err != nil {
+		log.Fatalf("Invalid curve points: %v", err)
+	}
+	growth, err := curve.New(*curveKind, *speed, points)
+	if err != nil {
+		log.Fatalf("Invalid curve: %v", err)
+	}
+
+	if len(strategies) == 0 {
+		strategies = append(strategies, crash.DefaultStrategies...)
+	}
+	for i := range strategies {
+		strategies[i].Bet = *bet
+	}
+
+	if *seed == "" {
+		if *seed, err = fairness.GenerateSeed(); err != nil {
+			log.Fatalf("Failed to generate seed: %v", err)
+		}
+	}
+
+	settings := crash.Settings{
+		Params: fairness.Params{
+			MaxMultiplier:      *maxMultiplier,
+			HouseEdge:          *houseEdge,
+			InstantCrashChance: *instantCrash,
+			Distribution:       *distribution,
+			Shape:              *shape,
+		},
+		Curve:        growth,
+		MaxWinPerBet: *maxWin,
+	}
+	report := crash.Simulate(settings, strategies, *rounds, *seed)
+
+	if *out == "" {
+		err = writeReport(os.Stdout, *format, report)
+	} else {
+		err = writeFile(*out, *format, report)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
+
+// writeFile writes the report to path. The file is closed before returning so
+// that a failed flush to disk is reported like any other write error.
+func writeFile(path, format string, report crash.Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = writeReport(file, format, report)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func writeReport(w io.Writer, format string, report crash.Report) error {
+	if format == "csv" {
+		return writeCSV(w, report)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// writeCSV writes one summary row per strategy, a blank line, and then the
+// payout distribution of every strategy and the crash point distribution in
+// long form.
+func writeCSV(w io.Writer, report crash.Report) error {
+	writer := csv.NewWriter(w)
+
+	writer.Write([]string{"strategy", "rounds", "bets", "total_bet", "total_payout", "rtp", "theoretical_rtp", "hit_rate", "variance", "std_dev", "max_drawdown"})
+	for _, result := range report.Strategies {
+		theoretical := ""
+		if result.TheoreticalRTP != nil {
+			theoretical = formatFloat(*result.TheoreticalRTP)
+		}
+		writer.Write([]string{
+			result.Name,
+			strconv.Itoa(report.Rounds),
+			strconv.Itoa(result.Bets),
+			formatFloat(result.TotalBet),
+			formatFloat(result.TotalPayout),
+			formatFloat(result.RTP),
+			theoretical,
+			formatFloat(result.HitRate),
+			formatFloat(result.Variance),
+			formatFloat(result.StdDev),
+			formatFloat(result.MaxDrawdown),
+		})
+	}
+	writer.Flush()
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+
+	writer.Write([]string{"series", "from", "to", "count", "share"})
+	for _, result := range report.Strategies {
+		writeBuckets(writer, result.Name, result.Payouts)
+	}
+	writeBuckets(writer, "crash_point", report.CrashPoints)
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeBuckets(writer *csv.Writer, series string, buckets []crash.Bucket) {
+	for _, bucket := range buckets {
+		to := ""
+		if bucket.To != nil {
+			to = formatFloat(*bucket.To)
+		}
+		writer.Write([]string{series, formatFloat(bucket.From), to, strconv.Itoa(bucket.Count), formatFloat(bucket.Share)})
+	}
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
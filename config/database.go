@@ -49,11 +49,13 @@ func ConnectDB() {
 
 	fmt.Println("Database connected successfully!")
 
-	err = db.AutoMigrate(&models.User{}, &models.Wallet{}, &models.Game{}, &models.GameSettings{}, &models.Transaction{}, &models.BlacklistedToken{})
+	err = db.AutoMigrate(&models.User{}, &models.Wallet{}, &models.Game{}, &models.GameSettings{}, &models.GameSettingsVersion{}, &models.ScheduledSettingsChange{}, &models.MaintenanceWindow{}, &models.Transaction{}, &models.BlacklistedToken{}, &models.ServerSeed{}, &models.UserSeed{}, &models.Round{}, &models.GameRecovery{}, &models.GameCashout{}, &models.DiceSettings{}, &models.DiceRoll{}, &models.MinesSettings{}, &models.MinesGame{}, &models.PlinkoPayoutTable{}, &models.PlinkoDrop{}, &models.RouletteSpin{}, &models.RouletteBet{}, &models.BlackjackSettings{}, &models.BlackjackShoe{}, &models.BlackjackGame{}, &models.SlotMachine{}, &models.SlotSpin{}, &models.KenoSettings{}, &models.KenoDraw{}, &models.KenoTicket{}, &models.JackpotPool{}, &models.JackpotEntry{}, &models.SchemaMigration{})
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	runMigrations(db)
+
 	fmt.Println("Database migration completed!")
 	DB = db
 }
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"casino_api_go/fairness"
+	"casino_api_go/models"
+
+	"gorm.io/gorm"
+)
+
+type migration struct {
+	name string
+	run  func(db *gorm.DB) error
+}
+
+// migrations are one-off data changes that AutoMigrate can't express. They
+// run once, in order, and are recorded in schema_migrations. Append new ones
+// at the end and never rename an applied one.
+var migrations = []migration{
+	{name: "backfill_transaction_game_type", run: backfillTransactionGameType},
+	{name: "retire_chained_server_seeds", run: retireChainedServerSeeds},
+}
+
+// runMigrations applies the migrations that have not been recorded yet. A
+// failed migration is logged and left unrecorded so it is retried on the next
+// boot; the ones after it wait for it.
+func runMigrations(db *gorm.DB) {
+	for _, m := range migrations {
+		var applied models.SchemaMigration
+		err := db.Where("name = ?", m.name).First(&applied).Error
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Failed to check migration %s: %v", m.name, err)
+			return
+		}
+
+		if err := m.run(db); err != nil {
+			log.Printf("Migration %s failed: %v", m.name, err)
+			return
+		}
+		if err := db.Create(&models.SchemaMigration{Name: m.name}).Error; err != nil {
+			log.Printf("Failed to record migration %s: %v", m.name, err)
+			return
+		}
+		log.Printf("Applied migration %s", m.name)
+	}
+}
+
+// backfillTransactionGameType tags wallet entries written before game_type
+// existed from the game they belong to or from their "<type>:<id>" reference.
+func backfillTransactionGameType(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("UPDATE transactions JOIN games ON games.id = transactions.game_id SET transactions.game_type = games.game_type WHERE transactions.game_type IS NULL OR transactions.game_type = ''").Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE transactions SET game_type = SUBSTRING_INDEX(reference, ':', 1) WHERE (game_type IS NULL OR game_type = '') AND reference LIKE '%:%'").Error
+	})
+}
+
+// retireChainedServerSeeds moves off the shared hash chain server seeds used
+// to be cut from. Any revealed seed of that chain gives away every seed before
+// it, so the unclaimed ones are deleted and the chain columns are dropped.
+// Every seed of the chain that is committed to but not played yet, a
+// player's next seed, an open round's or an open keno draw's, is replaced by
+// an independent one. The replaced seeds are marked retired and revealed, so
+// the old commitments can still be checked. Seeds that were already played
+// stay for verification.
+func retireChainedServerSeeds(db *gorm.DB) error {
+	if err := db.Exec("DELETE FROM server_seeds WHERE user_id IS NULL AND round_id IS NULL AND keno_draw_id IS NULL").Error; err != nil {
+		return err
+	}
+
+	for _, column := range []string{"chain_index", "chain_hash"} {
+		if db.Migrator().HasColumn("server_seeds", column) {
+			if err := db.Migrator().DropColumn("server_seeds", column); err != nil {
+				return err
+			}
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var userSeeds []models.UserSeed
+		if err := tx.Find(&userSeeds).Error; err != nil {
+			return err
+		}
+		for _, userSeed := range userSeeds {
+			serverSeed, err := replaceServerSeed(tx, &userSeed.ServerSeedID, models.ServerSeed{UserID: &userSeed.UserID}, now)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.UserSeed{}).Where("id = ?", userSeed.ID).Update("server_seed_id", serverSeed.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		var rounds []models.Round
+		if err := tx.Where("status IN ?", []string{"betting", "running"}).Find(&rounds).Error; err != nil {
+			return err
+		}
+		for _, round := range rounds {
+			if err := rotateRoundSeed(tx, round, now); err != nil {
+				return err
+			}
+		}
+
+		var draws []models.KenoDraw
+		if err := tx.Where("status = ?", "open").Find(&draws).Error; err != nil {
+			return err
+		}
+		for _, draw := range draws {
+			serverSeed, err := replaceServerSeed(tx, draw.ServerSeedID, models.ServerSeed{KenoDrawID: &draw.ID, UsedAt: &now}, now)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.KenoDraw{}).Where("id = ?", draw.ID).Updates(map[string]interface{}{
+				"server_seed_id":   serverSeed.ID,
+				"server_seed_hash": serverSeed.Hash,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// replaceServerSeed retires the seed with id oldID, if any, and stores a
+// fresh one for the owner set on next.
+func replaceServerSeed(tx *gorm.DB, oldID *uint, next models.ServerSeed, now time.Time) (*models.ServerSeed, error) {
+	if oldID != nil {
+		if err := tx.Model(&models.ServerSeed{}).Where("id = ?", *oldID).Updates(map[string]interface{}{
+			"used_at":    now,
+			"retired_at": now,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	seed, err := fairness.GenerateSeed()
+	if err != nil {
+		return nil, err
+	}
+	next.Seed = seed
+	next.Hash = fairness.HashSeed(seed)
+	if err := tx.Create(&next).Error; err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// rotateRoundSeed gives a round that has not crashed yet a fresh seed and the
+// crash point that comes with it, on the settings it was opened with. The
+// bets already in the round follow it.
+func rotateRoundSeed(tx *gorm.DB, round models.Round, now time.Time) error {
+	var settings models.GameSettings
+	if round.SettingsSnapshot == "" || json.Unmarshal([]byte(round.SettingsSnapshot), &settings) != nil {
+		if err := tx.Order("id DESC").First(&settings).Error; err != nil {
+			return err
+		}
+	}
+
+	serverSeed, err := replaceServerSeed(tx, round.ServerSeedID, models.ServerSeed{RoundID: &round.ID, UsedAt: &now}, now)
+	if err != nil {
+		return err
+	}
+	crashPoint := fairness.CrashPoint(fairness.GameHash(serverSeed.Seed, round.ClientSeed, 0), fairness.Params{
+		MaxMultiplier:      settings.MaxMultiplier,
+		HouseEdge:          settings.HouseEdge,
+		InstantCrashChance: settings.InstantCrashChance,
+		Distribution:       settings.Distribution,
+		Shape:              settings.DistributionShape,
+	})
+
+	seedFields := map[string]interface{}{
+		"server_seed_id":   serverSeed.ID,
+		"server_seed_hash": serverSeed.Hash,
+		"crash_point":      crashPoint,
+	}
+	if err := tx.Model(&models.Round{}).Where("id = ?", round.ID).Updates(seedFields).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Game{}).Where("round_id = ?", round.ID).Updates(seedFields).Error
+}
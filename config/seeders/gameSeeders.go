@@ -2,8 +2,15 @@ package seeders
 
 import (
 	"casino_api_go/config"
+	"casino_api_go/games/keno"
+	"casino_api_go/games/slots"
 	"casino_api_go/models"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
 	"log"
+	"time"
 )
 
 func SeedGameSettings() {
@@ -14,6 +21,10 @@ func SeedGameSettings() {
 
 	if count > 0 {
 		log.Println("Game settings already exist, skipping...")
+		var existing models.GameSettings
+		if err := config.DB.Order("id DESC").First(&existing).Error; err == nil {
+			seedGameSettingsVersion(existing)
+		}
 		return
 	}
 
@@ -23,20 +34,347 @@ func SeedGameSettings() {
 		MaxBetAmount:    1000000.0,
 		MultiplierSpeed: 0.1,
 		IsActive:        true,
+
+		HouseEdge:          1.0,
+		InstantCrashChance: 0,
+		Distribution:       "standard",
+		DistributionShape:  2.0,
+		Curve:              "linear",
+
+		MaxWinPerBet:      50000000.0,
+		MaxRoundLiability: 500000000.0,
 	}
 
 	if err := config.DB.Create(&gameSettings).Error; err != nil {
 		log.Printf("Error creating game settings: %v", err)
 		return
 	}
+	seedGameSettingsVersion(gameSettings)
 
 	log.Println("Game settings seeded successfully!")
 	log.Printf("Max Multiplier: %.2fx", gameSettings.MaxMultiplier)
 	log.Printf("Min Bet Amount: %.2f IDR", gameSettings.MinBetAmount)
 	log.Printf("Max Bet Amount: %.2f IDR", gameSettings.MaxBetAmount)
 	log.Printf("Multiplier Speed: %.2f per second", gameSettings.MultiplierSpeed)
+	log.Printf("House Edge: %.2f%%", gameSettings.HouseEdge)
+	log.Printf("Distribution: %s", gameSettings.Distribution)
+	log.Printf("Curve: %s", gameSettings.Curve)
+	log.Printf("Max Win Per Bet: %.2f IDR", gameSettings.MaxWinPerBet)
+	log.Printf("Max Round Liability: %.2f IDR", gameSettings.MaxRoundLiability)
+}
+
+// seedGameSettingsVersion records settings as the first entry of the version
+// history, so the settings in place before any admin change can be restored.
+func seedGameSettingsVersion(settings models.GameSettings) {
+	var versions int64
+	config.DB.Model(&models.GameSettingsVersion{}).Count(&versions)
+	if versions > 0 {
+		return
+	}
+
+	encoded, _ := json.Marshal(settings)
+	version := models.GameSettingsVersion{
+		SettingsID: settings.ID,
+		Action:     "seed",
+		Reason:     "Initial game settings",
+		Settings:   string(encoded),
+	}
+	if err := config.DB.Create(&version).Error; err != nil {
+		log.Printf("Error recording game settings version: %v", err)
+	}
+}
+
+func SeedDiceSettings() {
+	log.Println("Seeding dice settings...")
+
+	var count int64
+	config.DB.Model(&models.DiceSettings{}).Count(&count)
+
+	if count > 0 {
+		log.Println("Dice settings already exist, skipping...")
+		return
+	}
+
+	diceSettings := models.DiceSettings{
+		MinBetAmount: 1000.0,
+		MaxBetAmount: 1000000.0,
+		HouseEdge:    1.0,
+		MinWinChance: 1.0,
+		MaxWinChance: 95.0,
+		IsActive:     true,
+	}
+
+	if err := config.DB.Create(&diceSettings).Error; err != nil {
+		log.Printf("Error creating dice settings: %v", err)
+		return
+	}
+
+	log.Println("Dice settings seeded successfully!")
+	log.Printf("Win Chance: %.2f%% - %.2f%%", diceSettings.MinWinChance, diceSettings.MaxWinChance)
+	log.Printf("House Edge: %.2f%%", diceSettings.HouseEdge)
+}
+
+func SeedMinesSettings() {
+	log.Println("Seeding mines settings...")
+
+	var count int64
+	config.DB.Model(&models.MinesSettings{}).Count(&count)
+
+	if count > 0 {
+		log.Println("Mines settings already exist, skipping...")
+		return
+	}
+
+	minesSettings := models.MinesSettings{
+		MinBetAmount: 1000.0,
+		MaxBetAmount: 1000000.0,
+		HouseEdge:    1.0,
+		MinMines:     1,
+		MaxMines:     24,
+		IsActive:     true,
+	}
+
+	if err := config.DB.Create(&minesSettings).Error; err != nil {
+		log.Printf("Error creating mines settings: %v", err)
+		return
+	}
+
+	log.Println("Mines settings seeded successfully!")
+	log.Printf("Mines: %d - %d", minesSettings.MinMines, minesSettings.MaxMines)
+	log.Printf("House Edge: %.2f%%", minesSettings.HouseEdge)
+}
+
+func SeedBlackjackSettings() {
+	log.Println("Seeding blackjack settings...")
+
+	var count int64
+	config.DB.Model(&models.BlackjackSettings{}).Count(&count)
+
+	if count > 0 {
+		log.Println("Blackjack settings already exist, skipping...")
+		return
+	}
+
+	blackjackSettings := models.BlackjackSettings{
+		MinBetAmount:     1000.0,
+		MaxBetAmount:     1000000.0,
+		Decks:            6,
+		Penetration:      0.75,
+		DealerHitsSoft17: false,
+		IsActive:         true,
+	}
+
+	if err := config.DB.Create(&blackjackSettings).Error; err != nil {
+		log.Printf("Error creating blackjack settings: %v", err)
+		return
+	}
+
+	log.Println("Blackjack settings seeded successfully!")
+	log.Printf("Decks: %d (reshuffle at %.0f%%)", blackjackSettings.Decks, blackjackSettings.Penetration*100)
+}
+
+// defaultPlinkoTables holds the payout multipliers per slot for every row count
+// and risk level. Every table returns about 99% to the player.
+var defaultPlinkoTables = map[int]map[string][]float64{
+	8: {
+		"low":    {5.6, 2.1, 1.1, 1, 0.5, 1, 1.1, 2.1, 5.6},
+		"medium": {13, 3, 1.3, 0.7, 0.4, 0.7, 1.3, 3, 13},
+		"high":   {29, 4, 1.5, 0.3, 0.2, 0.3, 1.5, 4, 29},
+	},
+	9: {
+		"low":    {5.6, 2, 1.6, 1, 0.7, 0.7, 1, 1.6, 2, 5.6},
+		"medium": {18, 4, 1.7, 0.9, 0.5, 0.5, 0.9, 1.7, 4, 18},
+		"high":   {43, 7, 2, 0.6, 0.2, 0.2, 0.6, 2, 7, 43},
+	},
+	10: {
+		"low":    {8.9, 3, 1.4, 1.1, 1, 0.5, 1, 1.1, 1.4, 3, 8.9},
+		"medium": {22, 5, 2, 1.4, 0.6, 0.4, 0.6, 1.4, 2, 5, 22},
+		"high":   {76, 10, 3, 0.9, 0.3, 0.2, 0.3, 0.9, 3, 10, 76},
+	},
+	11: {
+		"low":    {8.4, 3, 1.9, 1.3, 1, 0.7, 0.7, 1, 1.3, 1.9, 3, 8.4},
+		"medium": {24, 6, 3, 1.8, 0.7, 0.5, 0.5, 0.7, 1.8, 3, 6, 24},
+		"high":   {120, 14, 5.2, 1.4, 0.4, 0.2, 0.2, 0.4, 1.4, 5.2, 14, 120},
+	},
+	12: {
+		"low":    {10, 3, 1.6, 1.4, 1.1, 1, 0.5, 1, 1.1, 1.4, 1.6, 3, 10},
+		"medium": {33, 11, 4, 2, 1.1, 0.6, 0.3, 0.6, 1.1, 2, 4, 11, 33},
+		"high":   {170, 24, 8.1, 2, 0.7, 0.2, 0.2, 0.2, 0.7, 2, 8.1, 24, 170},
+	},
+	13: {
+		"low":    {8.1, 4, 3, 1.9, 1.2, 0.9, 0.7, 0.7, 0.9, 1.2, 1.9, 3, 4, 8.1},
+		"medium": {43, 13, 6, 3, 1.3, 0.7, 0.4, 0.4, 0.7, 1.3, 3, 6, 13, 43},
+		"high":   {260, 37, 11, 4, 1, 0.2, 0.2, 0.2, 0.2, 1, 4, 11, 37, 260},
+	},
+	14: {
+		"low":    {7.1, 4, 1.9, 1.4, 1.3, 1.1, 1, 0.5, 1, 1.1, 1.3, 1.4, 1.9, 4, 7.1},
+		"medium": {58, 15, 7, 4, 1.9, 1, 0.5, 0.2, 0.5, 1, 1.9, 4, 7, 15, 58},
+		"high":   {420, 56, 18, 5, 1.9, 0.3, 0.2, 0.2, 0.2, 0.3, 1.9, 5, 18, 56, 420},
+	},
+	15: {
+		"low":    {15, 8, 3, 2, 1.5, 1.1, 1, 0.7, 0.7, 1, 1.1, 1.5, 2, 3, 8, 15},
+		"medium": {88, 18, 11, 5, 3, 1.3, 0.5, 0.3, 0.3, 0.5, 1.3, 3, 5, 11, 18, 88},
+		"high":   {620, 83, 27, 8, 3, 0.5, 0.2, 0.2, 0.2, 0.2, 0.5, 3, 8, 27, 83, 620},
+	},
+	16: {
+		"low":    {16, 9, 2, 1.4, 1.4, 1.2, 1.1, 1, 0.5, 1, 1.1, 1.2, 1.4, 1.4, 2, 9, 16},
+		"medium": {110, 41, 10, 5, 3, 1.5, 1, 0.5, 0.3, 0.5, 1, 1.5, 3, 5, 10, 41, 110},
+		"high":   {1000, 130, 26, 9, 4, 2, 0.2, 0.2, 0.2, 0.2, 0.2, 2, 4, 9, 26, 130, 1000},
+	},
+}
+
+func SeedPlinkoTables() {
+	log.Println("Seeding plinko payout tables...")
+
+	var count int64
+	config.DB.Model(&models.PlinkoPayoutTable{}).Count(&count)
+
+	if count > 0 {
+		log.Println("Plinko payout tables already exist, skipping...")
+		return
+	}
+
+	var tables []models.PlinkoPayoutTable
+	for rows := 8; rows <= 16; rows++ {
+		for _, risk := range []string{"low", "medium", "high"} {
+			multipliers, _ := json.Marshal(defaultPlinkoTables[rows][risk])
+			tables = append(tables, models.PlinkoPayoutTable{
+				Rows:        rows,
+				Risk:        risk,
+				Multipliers: string(multipliers),
+			})
+		}
+	}
+
+	if err := config.DB.Create(&tables).Error; err != nil {
+		log.Printf("Error creating plinko payout tables: %v", err)
+		return
+	}
+
+	log.Printf("Plinko payout tables seeded successfully! (%d tables)", len(tables))
+}
+
+// classicFruitsConfig is the default slot machine, in the same YAML format
+// admins upload.
+//
+//go:embed classic_fruits.yaml
+var classicFruitsConfig string
+
+func SeedSlotMachines() {
+	log.Println("Seeding slot machines...")
+
+	var count int64
+	config.DB.Model(&models.SlotMachine{}).Count(&count)
+
+	if count > 0 {
+		log.Println("Slot machines already exist, skipping...")
+		return
+	}
+
+	machineConfig, err := slots.Parse([]byte(classicFruitsConfig), slots.FormatYAML)
+	if err != nil {
+		log.Printf("Error parsing default slot config: %v", err)
+		return
+	}
+
+	// The default machine is simulated like any uploaded one and only goes
+	// live when it pays back less than 100%.
+	simulation := machineConfig.Simulate(1000000, 1)
+	now := time.Now()
+	hash := sha256.Sum256([]byte(classicFruitsConfig))
+
+	machine := models.SlotMachine{
+		Name:             machineConfig.Name,
+		Format:           slots.FormatYAML,
+		Config:           classicFruitsConfig,
+		ConfigHash:       hex.EncodeToString(hash[:]),
+		MinBet:           machineConfig.MinBet,
+		MaxBet:           machineConfig.MaxBet,
+		Status:           "simulated",
+		SimulatedRTP:     &simulation.RTP,
+		SimulatedHitRate: &simulation.HitRate,
+		SimulationSpins:  simulation.Spins,
+		SimulatedAt:      &now,
+	}
+	if simulation.RTP < 100 {
+		machine.Status = "active"
+	}
+
+	if err := config.DB.Create(&machine).Error; err != nil {
+		log.Printf("Error creating slot machine: %v", err)
+		return
+	}
+
+	log.Println("Slot machines seeded successfully!")
+	log.Printf("%s: simulated RTP %.2f%% (%s)", machine.Name, simulation.RTP, machine.Status)
+}
+
+func SeedKenoSettings() {
+	log.Println("Seeding keno settings...")
+
+	var count int64
+	config.DB.Model(&models.KenoSettings{}).Count(&count)
+
+	if count > 0 {
+		log.Println("Keno settings already exist, skipping...")
+		return
+	}
+
+	paytable, _ := json.Marshal(keno.DefaultPaytable)
+	kenoSettings := models.KenoSettings{
+		MinBetAmount: 1000.0,
+		MaxBetAmount: 1000000.0,
+		DrawInterval: 60,
+		Paytable:     string(paytable),
+		IsActive:     true,
+	}
+
+	if err := config.DB.Create(&kenoSettings).Error; err != nil {
+		log.Printf("Error creating keno settings: %v", err)
+		return
+	}
+
+	log.Println("Keno settings seeded successfully!")
+	log.Printf("Draw Interval: %d seconds", kenoSettings.DrawInterval)
+}
+
+func SeedJackpotPool() {
+	log.Println("Seeding jackpot pool...")
+
+	var count int64
+	config.DB.Model(&models.JackpotPool{}).Count(&count)
+
+	if count > 0 {
+		log.Println("Jackpot pool already exists, skipping...")
+		return
+	}
+
+	pool := models.JackpotPool{
+		Balance:          1000000.0,
+		SeedAmount:       1000000.0,
+		ContributionRate: 0.5,
+		TriggerChance:    0.01,
+		MinBetAmount:     1000.0,
+		IsActive:         true,
+		TotalSeeded:      1000000.0,
+	}
+
+	if err := config.DB.Create(&pool).Error; err != nil {
+		log.Printf("Error creating jackpot pool: %v", err)
+		return
+	}
+
+	log.Println("Jackpot pool seeded successfully!")
+	log.Printf("Contribution: %.2f%%, Trigger Chance: %.4f%%", pool.ContributionRate, pool.TriggerChance)
 }
 
 func SeedAllGameData() {
 	SeedGameSettings()
+	SeedDiceSettings()
+	SeedMinesSettings()
+	SeedPlinkoTables()
+	SeedBlackjackSettings()
+	SeedSlotMachines()
+	SeedKenoSettings()
+	SeedJackpotPool()
 }
@@ -7,6 +7,6 @@ import (
 func SeedAllData() {
 	log.Println("=== Starting Database Seeding ===")
 	SeedUsers()
-	SeedGameSettings()
+	SeedAllGameData()
 	log.Println("=== Database Seeding Completed ===")
 }
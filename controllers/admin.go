@@ -2,11 +2,26 @@ package controllers
 
 import (
 	"casino_api_go/config"
+	"casino_api_go/curve"
+	"casino_api_go/fairness"
+	"casino_api_go/games/crash"
+	"casino_api_go/games/keno"
+	"casino_api_go/games/slots"
 	"casino_api_go/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func AdminMiddleware() gin.HandlerFunc {
@@ -381,6 +396,7 @@ func GetWalletHistory(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	transactionType := c.Query("type")
+	gameType := c.Query("game_type")
 
 	offset := (page - 1) * limit
 
@@ -402,6 +418,10 @@ func GetWalletHistory(c *gin.Context) {
 		query = query.Where("type = ?", transactionType)
 	}
 
+	if gameType != "" {
+		query = query.Where("game_type = ?", gameType)
+	}
+
 	query.Count(&total)
 	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&transactions).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, AuthResponse{
@@ -416,6 +436,7 @@ func GetWalletHistory(c *gin.Context) {
 		transactionData = append(transactionData, gin.H{
 			"id":          transaction.ID,
 			"type":        transaction.Type,
+			"game_type":   transaction.GameType,
 			"amount":      transaction.Amount,
 			"balance":     transaction.Balance,
 			"description": transaction.Description,
@@ -463,9 +484,20 @@ func GetDashboardStats(c *gin.Context) {
 	config.DB.Model(&models.User{}).Where("status = ?", "banned").Count(&bannedUsers)
 	config.DB.Model(&models.Wallet{}).Select("COALESCE(SUM(balance), 0)").Scan(&totalBalance)
 	config.DB.Model(&models.Game{}).Count(&totalGames)
-	config.DB.Model(&models.Game{}).Select("COALESCE(SUM(bet_amount), 0)").Scan(&totalBets)
+	config.DB.Model(&models.Game{}).Where("status <> ?", "refunded").Select("COALESCE(SUM(bet_amount), 0)").Scan(&totalBets)
 	config.DB.Model(&models.Game{}).Select("COALESCE(SUM(win_amount), 0)").Scan(&totalWins)
 
+	liability := gin.H{}
+	if settings, err := loadGameSettings(); err == nil {
+		liability["open_liability"] = openLiability(0)
+		liability["max_win_per_bet"] = settings.MaxWinPerBet
+		liability["max_round_liability"] = settings.MaxRoundLiability
+		if round := scheduler.currentRound(); round != nil && round.Status != "crashed" {
+			liability["round_id"] = round.ID
+			liability["round_liability"] = openLiability(round.ID)
+		}
+	}
+
 	c.JSON(http.StatusOK, AuthResponse{
 		Success: true,
 		Message: "Dashboard statistics retrieved successfully",
@@ -481,6 +513,7 @@ func GetDashboardStats(c *gin.Context) {
 				"house_profit":  totalBets - totalWins,
 				"currency":      "IDR",
 			},
+			"liability": liability,
 		},
 	})
 }
@@ -491,6 +524,22 @@ type UpdateGameSettingsRequest struct {
 	MaxBetAmount    float64 `json:"max_bet_amount" binding:"required,gt=0"`
 	MultiplierSpeed float64 `json:"multiplier_speed" binding:"required,gt=0"`
 	IsActive        bool    `json:"is_active"`
+
+	HouseEdge          *float64 `json:"house_edge" binding:"omitempty,gte=0,lte=20"`
+	InstantCrashChance *float64 `json:"instant_crash_chance" binding:"omitempty,gte=0,lte=50"`
+	Distribution       string   `json:"distribution" binding:"omitempty,oneof=standard pareto"`
+	DistributionShape  *float64 `json:"distribution_shape" binding:"omitempty,gte=1,lte=5"`
+
+	Curve       string        `json:"curve" binding:"omitempty,oneof=linear exponential piecewise"`
+	CurvePoints []curve.Point `json:"curve_points"`
+
+	MaxWinPerBet      *float64 `json:"max_win_per_bet" binding:"omitempty,gte=0"`
+	MaxRoundLiability *float64 `json:"max_round_liability" binding:"omitempty,gte=0"`
+
+	Reason string `json:"reason" binding:"omitempty,max=255"`
+
+	// EffectiveAt schedules the change instead of applying it right away.
+	EffectiveAt *time.Time `json:"effective_at"`
 }
 
 func UpdateGameSettings(c *gin.Context) {
@@ -503,82 +552,219 @@ func UpdateGameSettings(c *gin.Context) {
 		return
 	}
 
-	if req.MinBetAmount >= req.MaxBetAmount {
+	curvePoints, err := validateGameSettings(req)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, AuthResponse{
 			Success: false,
-			Message: "Min bet amount must be less than max bet amount",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	if req.MultiplierSpeed > 10.0 {
-		c.JSON(http.StatusBadRequest, AuthResponse{
-			Success: false,
-			Message: "Multiplier speed cannot exceed 10.0 (too fast)",
+	// A change with a future effective_at is stored and applied by the
+	// scheduler instead.
+	if req.EffectiveAt != nil && req.EffectiveAt.After(time.Now()) {
+		change, err := scheduleSettingsChange(c.GetUint("user_id"), req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, AuthResponse{
+				Success: false,
+				Message: "Failed to schedule game settings change",
+			})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, AuthResponse{
+			Success: true,
+			Message: fmt.Sprintf("Game settings change scheduled for %s", change.EffectiveAt.Format(time.RFC3339)),
+			Data: gin.H{
+				"scheduled_change": scheduledSettingsChangeData(*change),
+			},
 		})
 		return
 	}
 
-	if req.MaxMultiplier < 1.1 || req.MaxMultiplier > 1000.0 {
-		c.JSON(http.StatusBadRequest, AuthResponse{
+	tx := config.DB.Begin()
+
+	settings, version, err := saveGameSettings(tx, req, curvePoints, c.GetUint("user_id"), "update")
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, AuthResponse{
 			Success: false,
-			Message: "Max multiplier must be between 1.1x and 1000.0x",
+			Message: "Failed to update game settings",
 		})
 		return
 	}
 
-	var settings models.GameSettings
-	if err := config.DB.Where("is_active = ?", true).First(&settings).Error; err != nil {
-		settings = models.GameSettings{
-			MaxMultiplier:   req.MaxMultiplier,
-			MinBetAmount:    req.MinBetAmount,
-			MaxBetAmount:    req.MaxBetAmount,
-			MultiplierSpeed: req.MultiplierSpeed,
-			IsActive:        req.IsActive,
-		}
-
-		if err := config.DB.Create(&settings).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, AuthResponse{
-				Success: false,
-				Message: "Failed to create game settings",
-			})
-			return
-		}
-	} else {
-		settings.MaxMultiplier = req.MaxMultiplier
-		settings.MinBetAmount = req.MinBetAmount
-		settings.MaxBetAmount = req.MaxBetAmount
-		settings.MultiplierSpeed = req.MultiplierSpeed
-		settings.IsActive = req.IsActive
-
-		if err := config.DB.Save(&settings).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, AuthResponse{
-				Success: false,
-				Message: "Failed to update game settings",
-			})
-			return
-		}
-	}
+	tx.Commit()
+	refreshGameSettings()
 
 	c.JSON(http.StatusOK, AuthResponse{
 		Success: true,
 		Message: "Game settings updated successfully",
 		Data: gin.H{
-			"settings": gin.H{
-				"id":               settings.ID,
-				"max_multiplier":   settings.MaxMultiplier,
-				"min_bet_amount":   settings.MinBetAmount,
-				"max_bet_amount":   settings.MaxBetAmount,
-				"multiplier_speed": settings.MultiplierSpeed,
-				"is_active":        settings.IsActive,
-			},
+			"settings": adminGameSettingsData(settings),
+			"version":  settingsVersionData(*version),
 		},
 	})
 }
 
+// validateGameSettings checks a settings update and returns the encoded curve
+// points it sets.
+func validateGameSettings(req UpdateGameSettingsRequest) (string, error) {
+	if req.MinBetAmount >= req.MaxBetAmount {
+		return "", errors.New("Min bet amount must be less than max bet amount")
+	}
+
+	if req.MultiplierSpeed > 10.0 {
+		return "", errors.New("Multiplier speed cannot exceed 10.0 (too fast)")
+	}
+
+	if req.MaxMultiplier < 1.1 || req.MaxMultiplier > 1000.0 {
+		return "", errors.New("Max multiplier must be between 1.1x and 1000.0x")
+	}
+
+	if req.MaxWinPerBet != nil && *req.MaxWinPerBet > 0 && *req.MaxWinPerBet < req.MaxBetAmount {
+		return "", errors.New("Max win per bet must be at least the max bet amount")
+	}
+
+	if req.MaxRoundLiability != nil && *req.MaxRoundLiability > 0 && *req.MaxRoundLiability < req.MaxBetAmount {
+		return "", errors.New("Max round liability must be at least the max bet amount")
+	}
+
+	if req.HouseEdge != nil {
+		if err := checkJackpotContribution(*req.HouseEdge); err != nil {
+			return "", err
+		}
+	}
+
+	var curvePoints string
+	if req.Curve != "" {
+		if _, err := curve.New(req.Curve, req.MultiplierSpeed, req.CurvePoints); err != nil {
+			return "", errors.New("Invalid curve: " + err.Error())
+		}
+		if req.Curve == curve.Piecewise {
+			encoded, _ := json.Marshal(req.CurvePoints)
+			curvePoints = string(encoded)
+		}
+	}
+
+	return curvePoints, nil
+}
+
+// checkJackpotContribution rejects a crash house edge that no longer covers
+// the jackpot contribution, which is paid out of it.
+func checkJackpotContribution(houseEdge float64) error {
+	var pool models.JackpotPool
+	if err := config.DB.Order("id DESC").First(&pool).Error; err != nil {
+		return nil
+	}
+	if pool.ContributionRate > 0 && pool.ContributionRate >= houseEdge {
+		return fmt.Errorf("House edge must stay above the jackpot contribution rate of %.2f%%", pool.ContributionRate)
+	}
+	return nil
+}
+
+// saveGameSettings applies req to the current settings inside tx, creating
+// them when none exist yet, and records the change as a new version.
+func saveGameSettings(tx *gorm.DB, req UpdateGameSettingsRequest, curvePoints string, adminID uint, action string) (models.GameSettings, *models.GameSettingsVersion, error) {
+	var previous *models.GameSettings
+	var settings models.GameSettings
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id DESC").First(&settings).Error; err != nil {
+		settings = defaultGameSettings()
+	} else {
+		current := settings
+		previous = &current
+	}
+
+	applyGameSettings(&settings, req, curvePoints)
+
+	if err := tx.Save(&settings).Error; err != nil {
+		return settings, nil, err
+	}
+
+	version, err := recordSettingsVersion(tx, adminID, action, req.Reason, previous, settings, nil)
+	if err != nil {
+		return settings, nil, err
+	}
+	return settings, version, nil
+}
+
+// defaultGameSettings are the values an update starts from when no settings
+// exist yet.
+func defaultGameSettings() models.GameSettings {
+	return models.GameSettings{
+		HouseEdge:         1.0,
+		Distribution:      fairness.DistributionStandard,
+		DistributionShape: 2.0,
+		Curve:             curve.Linear,
+	}
+}
+
+func applyGameSettings(settings *models.GameSettings, req UpdateGameSettingsRequest, curvePoints string) {
+	settings.MaxMultiplier = req.MaxMultiplier
+	settings.MinBetAmount = req.MinBetAmount
+	settings.MaxBetAmount = req.MaxBetAmount
+	settings.MultiplierSpeed = req.MultiplierSpeed
+	settings.IsActive = req.IsActive
+	applyDistributionSettings(settings, req)
+	applyCurveSettings(settings, req, curvePoints)
+	applyLimitSettings(settings, req)
+}
+
+func applyDistributionSettings(settings *models.GameSettings, req UpdateGameSettingsRequest) {
+	if req.HouseEdge != nil {
+		settings.HouseEdge = *req.HouseEdge
+	}
+	if req.InstantCrashChance != nil {
+		settings.InstantCrashChance = *req.InstantCrashChance
+	}
+	if req.Distribution != "" {
+		settings.Distribution = req.Distribution
+	}
+	if req.DistributionShape != nil {
+		settings.DistributionShape = *req.DistributionShape
+	}
+}
+
+func applyCurveSettings(settings *models.GameSettings, req UpdateGameSettingsRequest, curvePoints string) {
+	if req.Curve != "" {
+		settings.Curve = req.Curve
+		settings.CurvePoints = curvePoints
+	}
+}
+
+func applyLimitSettings(settings *models.GameSettings, req UpdateGameSettingsRequest) {
+	if req.MaxWinPerBet != nil {
+		settings.MaxWinPerBet = *req.MaxWinPerBet
+	}
+	if req.MaxRoundLiability != nil {
+		settings.MaxRoundLiability = *req.MaxRoundLiability
+	}
+}
+
+func adminGameSettingsData(settings models.GameSettings) gin.H {
+	return gin.H{
+		"id":                   settings.ID,
+		"max_multiplier":       settings.MaxMultiplier,
+		"min_bet_amount":       settings.MinBetAmount,
+		"max_bet_amount":       settings.MaxBetAmount,
+		"multiplier_speed":     settings.MultiplierSpeed,
+		"is_active":            settings.IsActive,
+		"house_edge":           settings.HouseEdge,
+		"instant_crash_chance": settings.InstantCrashChance,
+		"distribution":         settings.Distribution,
+		"distribution_shape":   settings.DistributionShape,
+		"curve":                settings.Curve,
+		"curve_points":         curvePointsData(settings),
+		"max_win_per_bet":      settings.MaxWinPerBet,
+		"max_round_liability":  settings.MaxRoundLiability,
+		"rtp_at_2x":            fairness.RTP(crashParams(settings), 2.0) * 100,
+	}
+}
+
 func GetAdminGameSettings(c *gin.Context) {
 	var settings models.GameSettings
-	if err := config.DB.Where("is_active = ?", true).First(&settings).Error; err != nil {
+	if err := config.DB.Order("id DESC").First(&settings).Error; err != nil {
 		c.JSON(http.StatusNotFound, AuthResponse{
 			Success: false,
 			Message: "Game settings not found",
@@ -590,67 +776,140 @@ func GetAdminGameSettings(c *gin.Context) {
 		Success: true,
 		Message: "Game settings retrieved successfully",
 		Data: gin.H{
-			"settings": gin.H{
-				"id":               settings.ID,
-				"max_multiplier":   settings.MaxMultiplier,
-				"min_bet_amount":   settings.MinBetAmount,
-				"max_bet_amount":   settings.MaxBetAmount,
-				"multiplier_speed": settings.MultiplierSpeed,
-				"is_active":        settings.IsActive,
-			},
+			"settings": adminGameSettingsData(settings),
 		},
 	})
 }
 
-func GetAllGames(c *gin.Context) {
+// recordSettingsVersion appends a change of the game settings to the version
+// history inside tx. previous is nil when the settings were just created.
+func recordSettingsVersion(tx *gorm.DB, adminID uint, action, reason string, previous *models.GameSettings, settings models.GameSettings, rolledBackTo *uint) (*models.GameSettingsVersion, error) {
+	version := models.GameSettingsVersion{
+		SettingsID:   settings.ID,
+		Action:       action,
+		Reason:       reason,
+		RolledBackTo: rolledBackTo,
+		Settings:     snapshotSettings(settings),
+	}
+	if adminID != 0 {
+		version.AdminID = &adminID
+	}
+	if previous != nil {
+		version.PreviousSettings = snapshotSettings(*previous)
+	}
+
+	if err := tx.Create(&version).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// settingsValues is the comparable part of adminGameSettingsData, without
+// the row id.
+func settingsValues(snapshot string) gin.H {
+	if snapshot == "" {
+		return nil
+	}
+	settings, err := decodeSettingsSnapshot(snapshot)
+	if err != nil {
+		return nil
+	}
+	values := adminGameSettingsData(settings)
+	delete(values, "id")
+	return values
+}
+
+// diffSettings lists the fields that differ between two settings values,
+// sorted by field name.
+func diffSettings(from, to gin.H) []gin.H {
+	fields := make(map[string]bool)
+	for field := range from {
+		fields[field] = true
+	}
+	for field := range to {
+		fields[field] = true
+	}
+
+	var names []string
+	for field := range fields {
+		if !reflect.DeepEqual(from[field], to[field]) {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []gin.H{}
+	for _, field := range names {
+		changes = append(changes, gin.H{
+			"field": field,
+			"from":  from[field],
+			"to":    to[field],
+		})
+	}
+	return changes
+}
+
+func settingsVersionData(version models.GameSettingsVersion) gin.H {
+	previous := settingsValues(version.PreviousSettings)
+	settings := settingsValues(version.Settings)
+
+	data := gin.H{
+		"id":             version.ID,
+		"settings_id":    version.SettingsID,
+		"action":         version.Action,
+		"reason":         version.Reason,
+		"admin_id":       version.AdminID,
+		"rolled_back_to": version.RolledBackTo,
+		"previous":       previous,
+		"settings":       settings,
+		"changes":        diffSettings(previous, settings),
+		"created_at":     version.CreatedAt,
+	}
+	if version.Admin != nil {
+		data["admin"] = gin.H{
+			"id":       version.Admin.ID,
+			"username": version.Admin.Username,
+		}
+	}
+	return data
+}
+
+func GetGameSettingsVersions(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	status := c.Query("status")
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
 
 	offset := (page - 1) * limit
 
-	var games []models.Game
+	var versions []models.GameSettingsVersion
 	var total int64
 
-	query := config.DB.Model(&models.Game{}).Preload("User")
-
-	if status != "" {
-		query = query.Where("status = ?", status)
-	}
+	query := config.DB.Model(&models.GameSettingsVersion{})
 
 	query.Count(&total)
-	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&games).Error; err != nil {
+	if err := query.Preload("Admin").Offset(offset).Limit(limit).Order("id DESC").Find(&versions).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, AuthResponse{
 			Success: false,
-			Message: "Failed to retrieve games",
+			Message: "Failed to retrieve game settings versions",
 		})
 		return
 	}
 
-	var gameData []gin.H
-	for _, game := range games {
-		gameData = append(gameData, gin.H{
-			"id": game.ID,
-			"user": gin.H{
-				"id":       game.User.ID,
-				"username": game.User.Username,
-				"email":    game.User.Email,
-			},
-			"bet_amount":   game.BetAmount,
-			"multiplier":   game.Multiplier,
-			"win_amount":   game.WinAmount,
-			"status":       game.Status,
-			"is_completed": game.IsCompleted,
-			"created_at":   game.CreatedAt,
-			"updated_at":   game.UpdatedAt,
-		})
+	var versionData []gin.H
+	for _, version := range versions {
+		versionData = append(versionData, settingsVersionData(version))
 	}
 
 	c.JSON(http.StatusOK, AuthResponse{
 		Success: true,
-		Message: "Games retrieved successfully",
+		Message: "Game settings versions retrieved successfully",
 		Data: gin.H{
-			"games": gameData,
+			"versions": versionData,
 			"pagination": gin.H{
 				"page":       page,
 				"limit":      limit,
@@ -660,3 +919,1616 @@ func GetAllGames(c *gin.Context) {
 		},
 	})
 }
+
+// DiffGameSettingsVersions compares the settings as they were after two
+// versions, given as ?from=<id>&to=<id>.
+func DiffGameSettingsVersions(c *gin.Context) {
+	fromID, fromErr := strconv.ParseUint(c.Query("from"), 10, 64)
+	toID, toErr := strconv.ParseUint(c.Query("to"), 10, 64)
+	if fromErr != nil || toErr != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Query parameters from and to must be version ids",
+		})
+		return
+	}
+
+	var from, to models.GameSettingsVersion
+	if err := config.DB.First(&from, fromID).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Version given in from not found",
+		})
+		return
+	}
+	if err := config.DB.First(&to, toID).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Version given in to not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Game settings versions compared successfully",
+		Data: gin.H{
+			"from":    gin.H{"id": from.ID, "action": from.Action, "created_at": from.CreatedAt},
+			"to":      gin.H{"id": to.ID, "action": to.Action, "created_at": to.CreatedAt},
+			"changes": diffSettings(settingsValues(from.Settings), settingsValues(to.Settings)),
+		},
+	})
+}
+
+type RollbackGameSettingsRequest struct {
+	Reason string `json:"reason" binding:"omitempty,max=255"`
+}
+
+// RollbackGameSettings restores the settings as they were after an earlier
+// version. The rollback is recorded as a new version, so history is never
+// rewritten.
+func RollbackGameSettings(c *gin.Context) {
+	var req RollbackGameSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	var target models.GameSettingsVersion
+	if err := config.DB.First(&target, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Game settings version not found",
+		})
+		return
+	}
+
+	restored, err := decodeSettingsSnapshot(target.Settings)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Game settings version is invalid",
+		})
+		return
+	}
+
+	if err := checkJackpotContribution(restored.HouseEdge); err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	var settings models.GameSettings
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id DESC").First(&settings).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Game settings not found",
+		})
+		return
+	}
+	previous := settings
+
+	restored.Model = settings.Model
+	if err := tx.Save(&restored).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to update game settings",
+		})
+		return
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("Rollback to version %d", target.ID)
+	}
+	version, err := recordSettingsVersion(tx, c.GetUint("user_id"), "rollback", reason, &previous, restored, &target.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to record game settings version",
+		})
+		return
+	}
+
+	tx.Commit()
+	refreshGameSettings()
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: fmt.Sprintf("Game settings rolled back to version %d", target.ID),
+		Data: gin.H{
+			"settings": adminGameSettingsData(restored),
+			"version":  settingsVersionData(*version),
+		},
+	})
+}
+
+const defaultSimulationRounds = 1000000
+
+type SimulateGameSettingsRequest struct {
+	Settings   *UpdateGameSettingsRequest `json:"settings"`
+	Rounds     int                        `json:"rounds" binding:"omitempty,gte=10000,lte=5000000"`
+	Seed       string                     `json:"seed" binding:"omitempty,max=128"`
+	Strategies []crash.Strategy           `json:"strategies" binding:"omitempty,max=10"`
+}
+
+// SimulateGameSettings plays crash rounds offline on candidate settings, in
+// the same format as an update, or on the current settings when none are
+// given. Nothing is saved, so a change can be checked before it is made.
+func SimulateGameSettings(c *gin.Context) {
+	var req SimulateGameSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+	if req.Rounds == 0 {
+		req.Rounds = defaultSimulationRounds
+	}
+
+	settings, err := loadGameSettings()
+	if err != nil {
+		settings = defaultGameSettings()
+	}
+	if req.Settings != nil {
+		curvePoints, err := validateGameSettings(*req.Settings)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, AuthResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+		applyGameSettings(&settings, *req.Settings, curvePoints)
+	} else if err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Game settings not found",
+		})
+		return
+	}
+
+	strategies := req.Strategies
+	if len(strategies) == 0 {
+		strategies = append(strategies, crash.DefaultStrategies...)
+	}
+	for i := range strategies {
+		if strategies[i].Bet == 0 {
+			strategies[i].Bet = settings.MinBetAmount
+		}
+		if err := strategies[i].Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, AuthResponse{
+				Success: false,
+				Message: fmt.Sprintf("Invalid strategy %d: %s", i+1, err.Error()),
+			})
+			return
+		}
+	}
+
+	seed := req.Seed
+	if seed == "" {
+		if seed, err = fairness.GenerateSeed(); err != nil {
+			c.JSON(http.StatusInternalServerError, AuthResponse{
+				Success: false,
+				Message: "Failed to generate simulation seed",
+			})
+			return
+		}
+	}
+
+	report := crash.Simulate(crashSettings(settings), strategies, req.Rounds, seed)
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: fmt.Sprintf("Simulated %d rounds", report.Rounds),
+		Data: gin.H{
+			"settings":   adminGameSettingsData(settings),
+			"simulation": report,
+		},
+	})
+}
+
+func GetScheduledSettingsChanges(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	status := c.Query("status")
+
+	offset := (page - 1) * limit
+
+	var changes []models.ScheduledSettingsChange
+	var total int64
+
+	query := config.DB.Model(&models.ScheduledSettingsChange{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&total)
+	if err := query.Preload("Admin").Offset(offset).Limit(limit).Order("effective_at DESC").Find(&changes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to retrieve scheduled game settings changes",
+		})
+		return
+	}
+
+	var changeData []gin.H
+	for _, change := range changes {
+		changeData = append(changeData, scheduledSettingsChangeData(change))
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Scheduled game settings changes retrieved successfully",
+		Data: gin.H{
+			"scheduled_changes": changeData,
+			"pagination": gin.H{
+				"page":       page,
+				"limit":      limit,
+				"total":      total,
+				"total_page": (int(total) + limit - 1) / limit,
+			},
+		},
+	})
+}
+
+func CancelScheduledSettingsChange(c *gin.Context) {
+	var change models.ScheduledSettingsChange
+	if err := config.DB.First(&change, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Scheduled game settings change not found",
+		})
+		return
+	}
+
+	result := config.DB.Model(&change).Where("status = ?", "pending").Update("status", "cancelled")
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to cancel scheduled game settings change",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, AuthResponse{
+			Success: false,
+			Message: fmt.Sprintf("Scheduled game settings change is already %s", change.Status),
+		})
+		return
+	}
+	change.Status = "cancelled"
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Scheduled game settings change cancelled",
+		Data: gin.H{
+			"scheduled_change": scheduledSettingsChangeData(change),
+		},
+	})
+}
+
+type CreateMaintenanceWindowRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Message  string    `json:"message" binding:"omitempty,max=255"`
+}
+
+// CreateMaintenanceWindow schedules a pause of new bets and announces it to
+// live clients straight away.
+func CreateMaintenanceWindow(c *gin.Context) {
+	var req CreateMaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if !req.EndsAt.After(req.StartsAt) {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Maintenance must end after it starts",
+		})
+		return
+	}
+
+	if !req.EndsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Maintenance must end in the future",
+		})
+		return
+	}
+
+	window := models.MaintenanceWindow{
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Message:  req.Message,
+	}
+	if adminID := c.GetUint("user_id"); adminID != 0 {
+		window.AdminID = &adminID
+	}
+	if err := config.DB.Create(&window).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to create maintenance window",
+		})
+		return
+	}
+
+	refreshMaintenanceWindows()
+	scheduleMaintenanceEvents(window)
+	hub.broadcast("maintenance_scheduled", maintenanceData(window), false)
+
+	c.JSON(http.StatusCreated, AuthResponse{
+		Success: true,
+		Message: "Maintenance window scheduled successfully",
+		Data: gin.H{
+			"maintenance": maintenanceData(window),
+		},
+	})
+}
+
+func GetMaintenanceWindows(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	var windows []models.MaintenanceWindow
+	var total int64
+
+	query := config.DB.Model(&models.MaintenanceWindow{})
+
+	query.Count(&total)
+	if err := query.Offset(offset).Limit(limit).Order("starts_at DESC").Find(&windows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to retrieve maintenance windows",
+		})
+		return
+	}
+
+	var windowData []gin.H
+	for _, window := range windows {
+		windowData = append(windowData, maintenanceData(window))
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Maintenance windows retrieved successfully",
+		Data: gin.H{
+			"maintenance_windows": windowData,
+			"pagination": gin.H{
+				"page":       page,
+				"limit":      limit,
+				"total":      total,
+				"total_page": (int(total) + limit - 1) / limit,
+			},
+		},
+	})
+}
+
+// CancelMaintenanceWindow drops an announced window or ends a running one
+// early.
+func CancelMaintenanceWindow(c *gin.Context) {
+	var window models.MaintenanceWindow
+	if err := config.DB.First(&window, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Maintenance window not found",
+		})
+		return
+	}
+
+	now := time.Now()
+	if window.CancelledAt != nil || !now.Before(window.EndsAt) {
+		c.JSON(http.StatusConflict, AuthResponse{
+			Success: false,
+			Message: "Maintenance window has already ended",
+		})
+		return
+	}
+
+	if err := config.DB.Model(&window).Update("cancelled_at", now).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to cancel maintenance window",
+		})
+		return
+	}
+	window.CancelledAt = &now
+
+	refreshMaintenanceWindows()
+	hub.broadcast("maintenance_cancelled", maintenanceData(window), false)
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Maintenance window cancelled",
+		Data: gin.H{
+			"maintenance": maintenanceData(window),
+		},
+	})
+}
+
+func GetAllGames(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	status := c.Query("status")
+
+	offset := (page - 1) * limit
+
+	var games []models.Game
+	var total int64
+
+	query := config.DB.Model(&models.Game{}).Preload("User")
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if gameType := c.Query("game_type"); gameType != "" {
+		query = query.Where("game_type = ?", gameType)
+	}
+
+	query.Count(&total)
+	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&games).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to retrieve games",
+		})
+		return
+	}
+
+	var gameData []gin.H
+	for _, game := range games {
+		gameData = append(gameData, gin.H{
+			"id": game.ID,
+			"user": gin.H{
+				"id":       game.User.ID,
+				"username": game.User.Username,
+				"email":    game.User.Email,
+			},
+			"game_type":    game.GameType,
+			"bet_amount":   game.BetAmount,
+			"multiplier":   game.Multiplier,
+			"win_amount":   game.WinAmount,
+			"status":       game.Status,
+			"is_completed": game.IsCompleted,
+			"created_at":   game.CreatedAt,
+			"updated_at":   game.UpdatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Games retrieved successfully",
+		Data: gin.H{
+			"games": gameData,
+			"pagination": gin.H{
+				"page":       page,
+				"limit":      limit,
+				"total":      total,
+				"total_page": (int(total) + limit - 1) / limit,
+			},
+		},
+	})
+}
+
+func GetAllRounds(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	status := c.Query("status")
+
+	offset := (page - 1) * limit
+
+	var rounds []models.Round
+	var total int64
+
+	query := config.DB.Model(&models.Round{})
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&total)
+	if err := query.Offset(offset).Limit(limit).Order("id DESC").Find(&rounds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to retrieve rounds",
+		})
+		return
+	}
+
+	var roundData []gin.H
+	for _, round := range rounds {
+		roundData = append(roundData, gin.H{
+			"id":               round.ID,
+			"status":           round.Status,
+			"crash_point":      round.CrashPoint,
+			"betting_ends_at":  round.BettingEndsAt,
+			"started_at":       round.StartedAt,
+			"crashed_at":       round.CrashedAt,
+			"total_bets":       round.TotalBets,
+			"total_wagered":    round.TotalWagered,
+			"total_payout":     round.TotalPayout,
+			"house_profit":     round.TotalWagered - round.TotalPayout,
+			"server_seed_hash": round.ServerSeedHash,
+		})
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Rounds retrieved successfully",
+		Data: gin.H{
+			"rounds": roundData,
+			"pagination": gin.H{
+				"page":       page,
+				"limit":      limit,
+				"total":      total,
+				"total_page": (int(total) + limit - 1) / limit,
+			},
+		},
+	})
+}
+
+func GetRecoveredGames(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	action := c.Query("action")
+
+	offset := (page - 1) * limit
+
+	var recoveries []models.GameRecovery
+	var total int64
+
+	query := config.DB.Model(&models.GameRecovery{}).Preload("User")
+
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	query.Count(&total)
+	if err := query.Offset(offset).Limit(limit).Order("id DESC").Find(&recoveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to retrieve recovered games",
+		})
+		return
+	}
+
+	var recoveryData []gin.H
+	for _, recovery := range recoveries {
+		data := gin.H{
+			"id":           recovery.ID,
+			"game_id":      recovery.GameID,
+			"round_id":     recovery.RoundID,
+			"policy":       recovery.Policy,
+			"action":       recovery.Action,
+			"bet_amount":   recovery.BetAmount,
+			"amount":       recovery.Amount,
+			"note":         recovery.Note,
+			"recovered_at": recovery.CreatedAt,
+		}
+		if recovery.User != nil {
+			data["user"] = gin.H{
+				"id":       recovery.User.ID,
+				"username": recovery.User.Username,
+				"email":    recovery.User.Email,
+			}
+		}
+		recoveryData = append(recoveryData, data)
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Recovered games retrieved successfully",
+		Data: gin.H{
+			"recoveries": recoveryData,
+			"pagination": gin.H{
+				"page":       page,
+				"limit":      limit,
+				"total":      total,
+				"total_page": (int(total) + limit - 1) / limit,
+			},
+		},
+	})
+}
+
+type UpdateDiceSettingsRequest struct {
+	MinBetAmount float64  `json:"min_bet_amount" binding:"required,gt=0"`
+	MaxBetAmount float64  `json:"max_bet_amount" binding:"required,gt=0"`
+	HouseEdge    *float64 `json:"house_edge" binding:"omitempty,gte=0,lte=20"`
+	MinWinChance float64  `json:"min_win_chance" binding:"required,gte=0.01,lt=100"`
+	MaxWinChance float64  `json:"max_win_chance" binding:"required,gt=0,lte=99.99"`
+	IsActive     bool     `json:"is_active"`
+}
+
+func adminDiceSettingsData(settings models.DiceSettings) gin.H {
+	return gin.H{
+		"id":              settings.ID,
+		"min_bet_amount":  settings.MinBetAmount,
+		"max_bet_amount":  settings.MaxBetAmount,
+		"house_edge":      settings.HouseEdge,
+		"min_win_chance":  settings.MinWinChance,
+		"max_win_chance":  settings.MaxWinChance,
+		"is_active":       settings.IsActive,
+		"theoretical_rtp": 100 - settings.HouseEdge,
+	}
+}
+
+func GetAdminDiceSettings(c *gin.Context) {
+	settings, err := loadDiceSettings()
+	if err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Dice settings not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Dice settings retrieved successfully",
+		Data: gin.H{
+			"settings": adminDiceSettingsData(settings),
+		},
+	})
+}
+
+func UpdateDiceSettings(c *gin.Context) {
+	var req UpdateDiceSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if req.MinBetAmount >= req.MaxBetAmount {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Min bet amount must be less than max bet amount",
+		})
+		return
+	}
+
+	if req.MinWinChance >= req.MaxWinChance {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Min win chance must be less than max win chance",
+		})
+		return
+	}
+
+	settings, err := loadDiceSettings()
+	if err != nil {
+		settings = models.DiceSettings{HouseEdge: 1.0}
+	}
+
+	settings.MinBetAmount = req.MinBetAmount
+	settings.MaxBetAmount = req.MaxBetAmount
+	settings.MinWinChance = req.MinWinChance
+	settings.MaxWinChance = req.MaxWinChance
+	settings.IsActive = req.IsActive
+	if req.HouseEdge != nil {
+		settings.HouseEdge = *req.HouseEdge
+	}
+
+	if err := config.DB.Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to update dice settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Dice settings updated successfully",
+		Data: gin.H{
+			"settings": adminDiceSettingsData(settings),
+		},
+	})
+}
+
+type UpdateMinesSettingsRequest struct {
+	MinBetAmount float64  `json:"min_bet_amount" binding:"required,gt=0"`
+	MaxBetAmount float64  `json:"max_bet_amount" binding:"required,gt=0"`
+	HouseEdge    *float64 `json:"house_edge" binding:"omitempty,gte=0,lte=20"`
+	MinMines     int      `json:"min_mines" binding:"required,gte=1,lte=24"`
+	MaxMines     int      `json:"max_mines" binding:"required,gte=1,lte=24"`
+	IsActive     bool     `json:"is_active"`
+}
+
+func adminMinesSettingsData(settings models.MinesSettings) gin.H {
+	return gin.H{
+		"id":              settings.ID,
+		"min_bet_amount":  settings.MinBetAmount,
+		"max_bet_amount":  settings.MaxBetAmount,
+		"house_edge":      settings.HouseEdge,
+		"min_mines":       settings.MinMines,
+		"max_mines":       settings.MaxMines,
+		"is_active":       settings.IsActive,
+		"theoretical_rtp": 100 - settings.HouseEdge,
+	}
+}
+
+func GetAdminMinesSettings(c *gin.Context) {
+	settings, err := loadMinesSettings()
+	if err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Mines settings not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Mines settings retrieved successfully",
+		Data: gin.H{
+			"settings": adminMinesSettingsData(settings),
+		},
+	})
+}
+
+func UpdateMinesSettings(c *gin.Context) {
+	var req UpdateMinesSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if req.MinBetAmount >= req.MaxBetAmount {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Min bet amount must be less than max bet amount",
+		})
+		return
+	}
+
+	if req.MinMines > req.MaxMines {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Min mines cannot exceed max mines",
+		})
+		return
+	}
+
+	settings, err := loadMinesSettings()
+	if err != nil {
+		settings = models.MinesSettings{HouseEdge: 1.0}
+	}
+
+	settings.MinBetAmount = req.MinBetAmount
+	settings.MaxBetAmount = req.MaxBetAmount
+	settings.MinMines = req.MinMines
+	settings.MaxMines = req.MaxMines
+	settings.IsActive = req.IsActive
+	if req.HouseEdge != nil {
+		settings.HouseEdge = *req.HouseEdge
+	}
+
+	if err := config.DB.Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to update mines settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Mines settings updated successfully",
+		Data: gin.H{
+			"settings": adminMinesSettingsData(settings),
+		},
+	})
+}
+
+type UpdatePlinkoTableRequest struct {
+	Rows        int       `json:"rows" binding:"required,gte=8,lte=16"`
+	Risk        string    `json:"risk" binding:"required,oneof=low medium high"`
+	Multipliers []float64 `json:"multipliers" binding:"required,dive,gte=0"`
+}
+
+func UpdatePlinkoTable(c *gin.Context) {
+	var req UpdatePlinkoTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if len(req.Multipliers) != req.Rows+1 {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: fmt.Sprintf("A %d row table needs %d multipliers", req.Rows, req.Rows+1),
+		})
+		return
+	}
+
+	if rtp := fairness.PlinkoRTP(req.Multipliers); rtp >= 1 {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: fmt.Sprintf("Theoretical RTP of %.2f%% must stay below 100%%", rtp*100),
+		})
+		return
+	}
+
+	encoded, _ := json.Marshal(req.Multipliers)
+
+	var table models.PlinkoPayoutTable
+	if err := config.DB.Where("`rows` = ? AND risk = ?", req.Rows, req.Risk).First(&table).Error; err != nil {
+		table = models.PlinkoPayoutTable{Rows: req.Rows, Risk: req.Risk}
+	}
+	table.Multipliers = string(encoded)
+
+	if err := config.DB.Save(&table).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to update plinko payout table",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Plinko payout table updated successfully",
+		Data: gin.H{
+			"table": plinkoTableData(table),
+		},
+	})
+}
+
+type UpdateBlackjackSettingsRequest struct {
+	MinBetAmount     float64 `json:"min_bet_amount" binding:"required,gt=0"`
+	MaxBetAmount     float64 `json:"max_bet_amount" binding:"required,gt=0"`
+	Decks            int     `json:"decks" binding:"required,gte=1,lte=8"`
+	Penetration      float64 `json:"penetration" binding:"required,gte=0.5,lte=0.9"`
+	DealerHitsSoft17 bool    `json:"dealer_hits_soft_17"`
+	IsActive         bool    `json:"is_active"`
+}
+
+func adminBlackjackSettingsData(settings models.BlackjackSettings) gin.H {
+	return gin.H{
+		"id":                  settings.ID,
+		"min_bet_amount":      settings.MinBetAmount,
+		"max_bet_amount":      settings.MaxBetAmount,
+		"decks":               settings.Decks,
+		"penetration":         settings.Penetration,
+		"dealer_hits_soft_17": settings.DealerHitsSoft17,
+		"is_active":           settings.IsActive,
+	}
+}
+
+func GetAdminBlackjackSettings(c *gin.Context) {
+	settings, err := loadBlackjackSettings()
+	if err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Blackjack settings not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Blackjack settings retrieved successfully",
+		Data: gin.H{
+			"settings": adminBlackjackSettingsData(settings),
+		},
+	})
+}
+
+// UpdateBlackjackSettings changes the table rules. Shoes already in play keep
+// their deck count until they are reshuffled.
+func UpdateBlackjackSettings(c *gin.Context) {
+	var req UpdateBlackjackSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if req.MinBetAmount >= req.MaxBetAmount {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Min bet amount must be less than max bet amount",
+		})
+		return
+	}
+
+	settings, _ := loadBlackjackSettings()
+	settings.MinBetAmount = req.MinBetAmount
+	settings.MaxBetAmount = req.MaxBetAmount
+	settings.Decks = req.Decks
+	settings.Penetration = req.Penetration
+	settings.DealerHitsSoft17 = req.DealerHitsSoft17
+	settings.IsActive = req.IsActive
+
+	if err := config.DB.Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to update blackjack settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Blackjack settings updated successfully",
+		Data: gin.H{
+			"settings": adminBlackjackSettingsData(settings),
+		},
+	})
+}
+
+const (
+	maxSlotConfigSize      = 1 << 20
+	defaultSimulationSpins = 1000000
+	minSlotSimulationSpins = 100000
+	maxSlotSimulationSpins = 5000000
+	slotSimulationRandSeed = 1
+)
+
+type SimulateSlotMachineRequest struct {
+	Spins int `json:"spins" binding:"omitempty,gte=100000,lte=5000000"`
+}
+
+func adminSlotMachineData(machine models.SlotMachine) gin.H {
+	return gin.H{
+		"id":                 machine.ID,
+		"name":               machine.Name,
+		"format":             machine.Format,
+		"config":             machine.Config,
+		"config_hash":        machine.ConfigHash,
+		"min_bet":            machine.MinBet,
+		"max_bet":            machine.MaxBet,
+		"status":             machine.Status,
+		"simulated_rtp":      machine.SimulatedRTP,
+		"simulated_hit_rate": machine.SimulatedHitRate,
+		"simulation_spins":   machine.SimulationSpins,
+		"simulated_at":       machine.SimulatedAt,
+		"created_at":         machine.CreatedAt,
+		"updated_at":         machine.UpdatedAt,
+	}
+}
+
+// readSlotConfig reads an uploaded config from the request body. The format
+// comes from the format query parameter or, failing that, the content type.
+func readSlotConfig(c *gin.Context) (string, *slots.Config, string, error) {
+	format := c.Query("format")
+	if format == "" {
+		format = slots.FormatJSON
+		if strings.Contains(c.ContentType(), "yaml") {
+			format = slots.FormatYAML
+		}
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSlotConfigSize+1))
+	if err != nil {
+		return "", nil, "", err
+	}
+	if len(raw) > maxSlotConfigSize {
+		return "", nil, "", fmt.Errorf("config is larger than %d bytes", maxSlotConfigSize)
+	}
+
+	machineConfig, err := slots.Parse(raw, format)
+	if err != nil {
+		return "", nil, "", err
+	}
+	return format, machineConfig, string(raw), nil
+}
+
+func GetSlotMachinesAdmin(c *gin.Context) {
+	var machines []models.SlotMachine
+	if err := config.DB.Order("id ASC").Find(&machines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to retrieve slot machines",
+		})
+		return
+	}
+
+	var machineData []gin.H
+	for _, machine := range machines {
+		machineData = append(machineData, adminSlotMachineData(machine))
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Slot machines retrieved successfully",
+		Data: gin.H{
+			"machines": machineData,
+		},
+	})
+}
+
+// UploadSlotMachine creates a machine from a JSON or YAML config. New
+// machines start as drafts and have to be simulated before activation.
+func UploadSlotMachine(c *gin.Context) {
+	format, machineConfig, raw, err := readSlotConfig(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid slot config: " + err.Error(),
+		})
+		return
+	}
+
+	machine := models.SlotMachine{
+		Name:       machineConfig.Name,
+		Format:     format,
+		Config:     raw,
+		ConfigHash: slotConfigHash(raw),
+		MinBet:     machineConfig.MinBet,
+		MaxBet:     machineConfig.MaxBet,
+		Status:     "draft",
+	}
+	if err := config.DB.Create(&machine).Error; err != nil {
+		c.JSON(http.StatusConflict, AuthResponse{
+			Success: false,
+			Message: "Failed to create slot machine, the name may already be taken",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, AuthResponse{
+		Success: true,
+		Message: "Slot machine uploaded successfully",
+		Data: gin.H{
+			"machine": adminSlotMachineData(machine),
+		},
+	})
+}
+
+// UpdateSlotMachine replaces the config of a machine. Any earlier simulation
+// no longer applies, so the machine goes back to draft and off the floor.
+func UpdateSlotMachine(c *gin.Context) {
+	var machine models.SlotMachine
+	if err := config.DB.First(&machine, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Slot machine not found",
+		})
+		return
+	}
+
+	format, machineConfig, raw, err := readSlotConfig(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid slot config: " + err.Error(),
+		})
+		return
+	}
+
+	machine.Name = machineConfig.Name
+	machine.Format = format
+	machine.Config = raw
+	machine.ConfigHash = slotConfigHash(raw)
+	machine.MinBet = machineConfig.MinBet
+	machine.MaxBet = machineConfig.MaxBet
+	machine.Status = "draft"
+	machine.SimulatedRTP = nil
+	machine.SimulatedHitRate = nil
+	machine.SimulationSpins = 0
+	machine.SimulatedAt = nil
+
+	if err := config.DB.Save(&machine).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to update slot machine",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Slot machine updated successfully",
+		Data: gin.H{
+			"machine": adminSlotMachineData(machine),
+		},
+	})
+}
+
+// SimulateSlotMachine plays the config for the requested number of spins and
+// stores the measured RTP, which activation depends on.
+func SimulateSlotMachine(c *gin.Context) {
+	var req SimulateSlotMachineRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+	if req.Spins == 0 {
+		req.Spins = defaultSimulationSpins
+	}
+
+	var machine models.SlotMachine
+	if err := config.DB.First(&machine, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Slot machine not found",
+		})
+		return
+	}
+
+	machineConfig, err := parseSlotMachine(machine)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid slot config: " + err.Error(),
+		})
+		return
+	}
+
+	simulation := machineConfig.Simulate(req.Spins, slotSimulationRandSeed)
+	now := time.Now()
+
+	// The update is tied to the config hash so a config uploaded while the
+	// simulation ran doesn't inherit its result.
+	updates := map[string]interface{}{
+		"simulated_rtp":      simulation.RTP,
+		"simulated_hit_rate": simulation.HitRate,
+		"simulation_spins":   simulation.Spins,
+		"simulated_at":       now,
+	}
+	if machine.Status == "draft" {
+		updates["status"] = "simulated"
+	}
+	result := config.DB.Model(&models.SlotMachine{}).
+		Where("id = ? AND config_hash = ?", machine.ID, machine.ConfigHash).
+		Updates(updates)
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, AuthResponse{
+			Success: false,
+			Message: "Slot machine config changed during the simulation",
+		})
+		return
+	}
+
+	config.DB.First(&machine, machine.ID)
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: fmt.Sprintf("Simulated RTP is %.2f%% over %d spins", simulation.RTP, simulation.Spins),
+		Data: gin.H{
+			"machine":    adminSlotMachineData(machine),
+			"simulation": simulation,
+		},
+	})
+}
+
+// ActivateSlotMachine puts a simulated machine on the floor. Machines whose
+// simulation paid back 100% or more are refused.
+func ActivateSlotMachine(c *gin.Context) {
+	var machine models.SlotMachine
+	if err := config.DB.First(&machine, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Slot machine not found",
+		})
+		return
+	}
+
+	if machine.Status == "active" {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Slot machine is already active",
+		})
+		return
+	}
+
+	if machine.SimulatedRTP == nil || machine.SimulationSpins < minSlotSimulationSpins {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: fmt.Sprintf("Run a simulation of at least %d spins before activating", minSlotSimulationSpins),
+		})
+		return
+	}
+
+	if *machine.SimulatedRTP >= 100 {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: fmt.Sprintf("Simulated RTP of %.2f%% must stay below 100%%", *machine.SimulatedRTP),
+		})
+		return
+	}
+
+	// The config that was simulated is the one that goes live: an upload
+	// since the read above changes the hash and puts the machine back in
+	// draft, so the update matches nothing.
+	result := config.DB.Model(&models.SlotMachine{}).
+		Where("id = ? AND config_hash = ? AND status IN ?", machine.ID, machine.ConfigHash, []string{"simulated", "inactive"}).
+		Update("status", "active")
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to activate slot machine",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, AuthResponse{
+			Success: false,
+			Message: "Slot machine changed since it was simulated, simulate it again before activating",
+		})
+		return
+	}
+	machine.Status = "active"
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Slot machine activated successfully",
+		Data: gin.H{
+			"machine": adminSlotMachineData(machine),
+		},
+	})
+}
+
+func DeactivateSlotMachine(c *gin.Context) {
+	var machine models.SlotMachine
+	if err := config.DB.First(&machine, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Slot machine not found",
+		})
+		return
+	}
+
+	if machine.Status != "active" {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Slot machine is not active",
+		})
+		return
+	}
+
+	if err := config.DB.Model(&machine).Update("status", "inactive").Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to deactivate slot machine",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Slot machine deactivated successfully",
+		Data: gin.H{
+			"machine": adminSlotMachineData(machine),
+		},
+	})
+}
+
+type UpdateKenoSettingsRequest struct {
+	MinBetAmount float64           `json:"min_bet_amount" binding:"required,gt=0"`
+	MaxBetAmount float64           `json:"max_bet_amount" binding:"required,gt=0"`
+	DrawInterval int               `json:"draw_interval" binding:"required,gte=10,lte=3600"`
+	Paytable     map[int][]float64 `json:"paytable"`
+	IsActive     bool              `json:"is_active"`
+}
+
+func adminKenoSettingsData(settings models.KenoSettings) gin.H {
+	paytable, _ := decodeKenoPaytable(settings.Paytable)
+	return gin.H{
+		"id":             settings.ID,
+		"min_bet_amount": settings.MinBetAmount,
+		"max_bet_amount": settings.MaxBetAmount,
+		"draw_interval":  settings.DrawInterval,
+		"paytable":       kenoPaytableData(paytable),
+		"is_active":      settings.IsActive,
+	}
+}
+
+func GetAdminKenoSettings(c *gin.Context) {
+	settings, err := loadKenoSettings()
+	if err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Keno settings not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Keno settings retrieved successfully",
+		Data: gin.H{
+			"settings": adminKenoSettingsData(settings),
+		},
+	})
+}
+
+// UpdateKenoSettings changes the keno limits, schedule and paytable. Tickets
+// already sold keep the payouts they were bought with, and a new draw interval
+// applies from the next draw opened.
+func UpdateKenoSettings(c *gin.Context) {
+	var req UpdateKenoSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if req.MinBetAmount >= req.MaxBetAmount {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Min bet amount must be less than max bet amount",
+		})
+		return
+	}
+
+	settings, _ := loadKenoSettings()
+
+	if req.Paytable != nil {
+		paytable := keno.Paytable(req.Paytable)
+		if err := paytable.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, AuthResponse{
+				Success: false,
+				Message: "Invalid paytable: " + err.Error(),
+			})
+			return
+		}
+		for picks := 1; picks <= keno.MaxPicks; picks++ {
+			if rtp := paytable.RTP(picks); rtp >= 1 {
+				c.JSON(http.StatusBadRequest, AuthResponse{
+					Success: false,
+					Message: fmt.Sprintf("Theoretical RTP for %d picks of %.2f%% must stay below 100%%", picks, rtp*100),
+				})
+				return
+			}
+		}
+		encoded, _ := json.Marshal(paytable)
+		settings.Paytable = string(encoded)
+	}
+
+	if settings.Paytable == "" {
+		encoded, _ := json.Marshal(keno.DefaultPaytable)
+		settings.Paytable = string(encoded)
+	}
+
+	settings.MinBetAmount = req.MinBetAmount
+	settings.MaxBetAmount = req.MaxBetAmount
+	settings.DrawInterval = req.DrawInterval
+	settings.IsActive = req.IsActive
+
+	if err := config.DB.Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to update keno settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Keno settings updated successfully",
+		Data: gin.H{
+			"settings": adminKenoSettingsData(settings),
+		},
+	})
+}
+
+type UpdateJackpotSettingsRequest struct {
+	ContributionRate float64 `json:"contribution_rate" binding:"gte=0,lte=10"`
+	TriggerChance    float64 `json:"trigger_chance" binding:"gte=0,lte=1"`
+	SeedAmount       float64 `json:"seed_amount" binding:"gte=0"`
+	MinBetAmount     float64 `json:"min_bet_amount" binding:"gte=0"`
+	IsActive         bool    `json:"is_active"`
+}
+
+type SeedJackpotRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+func adminJackpotData(pool models.JackpotPool) gin.H {
+	data := jackpotData(pool)
+	data["id"] = pool.ID
+	data["seed_amount"] = pool.SeedAmount
+	data["total_contributed"] = pool.TotalContributed
+	data["total_seeded"] = pool.TotalSeeded
+	data["total_paid"] = pool.TotalPaid
+	data["updated_at"] = pool.UpdatedAt
+	return data
+}
+
+func GetAdminJackpot(c *gin.Context) {
+	var pool models.JackpotPool
+	if err := config.DB.Order("id DESC").First(&pool).Error; err != nil {
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Jackpot not found",
+		})
+		return
+	}
+
+	wins, err := recentJackpotWins(10)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to retrieve jackpot wins",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Jackpot retrieved successfully",
+		Data: gin.H{
+			"jackpot": adminJackpotData(pool),
+			"wins":    wins,
+		},
+	})
+}
+
+// UpdateJackpotSettings changes the contribution rate, trigger chance, seed
+// amount and minimum bet of the jackpot. The contribution is paid out of the
+// crash house edge, so it has to stay below it. A new pool starts at its seed
+// amount.
+func UpdateJackpotSettings(c *gin.Context) {
+	var req UpdateJackpotSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if settings, err := loadGameSettings(); err == nil && req.ContributionRate > 0 && req.ContributionRate >= settings.HouseEdge {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: fmt.Sprintf("Contribution rate must be below the crash house edge of %.2f%%", settings.HouseEdge),
+		})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	pool, err := lockJackpotPool(tx)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, AuthResponse{
+				Success: false,
+				Message: "Failed to load jackpot",
+			})
+			return
+		}
+		pool = &models.JackpotPool{
+			Balance:     req.SeedAmount,
+			TotalSeeded: req.SeedAmount,
+		}
+	}
+
+	pool.ContributionRate = req.ContributionRate
+	pool.TriggerChance = req.TriggerChance
+	pool.SeedAmount = req.SeedAmount
+	pool.MinBetAmount = req.MinBetAmount
+	pool.IsActive = req.IsActive
+
+	if err := tx.Save(pool).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to update jackpot",
+		})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Jackpot updated successfully",
+		Data: gin.H{
+			"jackpot": adminJackpotData(*pool),
+		},
+	})
+}
+
+// SeedJackpot adds house money to the pool straight away.
+func SeedJackpot(c *gin.Context) {
+	var req SeedJackpotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, AuthResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	pool, err := lockJackpotPool(tx)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, AuthResponse{
+			Success: false,
+			Message: "Jackpot not found",
+		})
+		return
+	}
+
+	pool.Balance += req.Amount
+	pool.TotalSeeded += req.Amount
+
+	if err := tx.Save(pool).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, AuthResponse{
+			Success: false,
+			Message: "Failed to seed jackpot",
+		})
+		return
+	}
+
+	tx.Commit()
+	hub.broadcast("jackpot", gin.H{"balance": pool.Balance}, true)
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Success: true,
+		Message: fmt.Sprintf("Jackpot seeded with %.2f", req.Amount),
+		Data: gin.H{
+			"jackpot": adminJackpotData(*pool),
+		},
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -302,6 +303,10 @@ func ValidateToken(tokenString string) (*Claims, error) {
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
+		if tokenString == "" && websocket.IsWebSocketUpgrade(c.Request) {
+			// Browsers cannot set headers on a WebSocket handshake.
+			tokenString = c.Query("token")
+		}
 		if tokenString == "" {
 			c.JSON(http.StatusUnauthorized, AuthResponse{
 				Success: false,
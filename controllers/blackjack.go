@@ -0,0 +1,652 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/games/blackjack"
+	"casino_api_go/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DealBlackjackRequest struct {
+	BetAmount float64 `json:"bet_amount" binding:"required,gt=0"`
+}
+
+type BlackjackActionRequest struct {
+	GameID uint `json:"game_id" binding:"required"`
+}
+
+type BlackjackInsuranceRequest struct {
+	GameID uint  `json:"game_id" binding:"required"`
+	Take   *bool `json:"take" binding:"required"`
+}
+
+func loadBlackjackSettings() (models.BlackjackSettings, error) {
+	var settings models.BlackjackSettings
+	err := config.DB.Order("id DESC").First(&settings).Error
+	return settings, err
+}
+
+// blackjackShoe deals cards from a stored shoe. When a hand needs more cards
+// than are left, the shoe is topped up with the next shuffle pass of the same
+// seed so the extra cards stay verifiable.
+type blackjackShoe struct {
+	model *models.BlackjackShoe
+	cards []int
+}
+
+func (s *blackjackShoe) draw() blackjack.Card {
+	if s.model.Position >= len(s.cards) {
+		pass := len(s.cards) / (s.model.Decks * 52)
+		s.cards = append(s.cards, fairness.ShuffleShoe(s.model.ServerSeed.Seed, s.model.ClientSeed, s.model.Nonce, s.model.Decks, pass)...)
+	}
+	card := blackjack.Card(s.cards[s.model.Position])
+	s.model.Position++
+	return card
+}
+
+func (s *blackjackShoe) save(tx *gorm.DB) error {
+	encoded, _ := json.Marshal(s.cards)
+	s.model.Cards = string(encoded)
+	return tx.Model(s.model).Updates(map[string]interface{}{
+		"cards":    s.model.Cards,
+		"position": s.model.Position,
+	}).Error
+}
+
+func loadBlackjackShoe(tx *gorm.DB, shoeID uint) (*blackjackShoe, error) {
+	var shoe models.BlackjackShoe
+	if err := tx.Preload("ServerSeed").First(&shoe, shoeID).Error; err != nil {
+		return nil, err
+	}
+	if shoe.ServerSeed == nil {
+		return nil, errors.New("shoe has no server seed")
+	}
+
+	var cards []int
+	if err := json.Unmarshal([]byte(shoe.Cards), &cards); err != nil {
+		return nil, err
+	}
+	return &blackjackShoe{model: &shoe, cards: cards}, nil
+}
+
+// activeBlackjackShoe returns the user's current shoe, retiring it and
+// shuffling a new one once the cut card is reached or the deck count changed.
+// The caller must hold the user's wallet lock.
+func activeBlackjackShoe(tx *gorm.DB, userID uint, settings models.BlackjackSettings) (*blackjackShoe, error) {
+	var current models.BlackjackShoe
+	err := tx.Where("user_id = ? AND status = ?", userID, "active").Order("id DESC").First(&current).Error
+	if err == nil {
+		if current.Position < current.CutCard && current.Decks == settings.Decks {
+			return loadBlackjackShoe(tx, current.ID)
+		}
+		if err := tx.Model(&current).Update("status", "retired").Error; err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	serverSeed, clientSeed, nonce, err := nextGameSeed(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := fairness.ShuffleShoe(serverSeed.Seed, clientSeed, nonce, settings.Decks, 0)
+	encoded, _ := json.Marshal(cards)
+	shoe := models.BlackjackShoe{
+		UserID:         userID,
+		Decks:          settings.Decks,
+		Cards:          string(encoded),
+		CutCard:        int(float64(len(cards)) * settings.Penetration),
+		Status:         "active",
+		ServerSeedID:   &serverSeed.ID,
+		ServerSeedHash: serverSeed.Hash,
+		ClientSeed:     clientSeed,
+		Nonce:          nonce,
+	}
+	if err := tx.Create(&shoe).Error; err != nil {
+		return nil, err
+	}
+
+	shoe.ServerSeed = serverSeed
+	return &blackjackShoe{model: &shoe, cards: cards}, nil
+}
+
+func decodeBlackjackState(game models.BlackjackGame) (*blackjack.Game, error) {
+	var state blackjack.Game
+	if err := json.Unmarshal([]byte(game.State), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func encodeBlackjackState(game *models.BlackjackGame, state *blackjack.Game) {
+	encoded, _ := json.Marshal(state)
+	game.State = string(encoded)
+	if state.Phase == blackjack.PhaseSettled {
+		game.Status = "settled"
+	}
+}
+
+// blackjackHand is the record of a blackjack outcome: the stored game and
+// the state it was decoded from.
+type blackjackHand struct {
+	game  models.BlackjackGame
+	state *blackjack.Game
+}
+
+// blackjackOutcome describes game as it stands after state. It stays pending
+// until the hand is settled.
+func blackjackOutcome(game *models.BlackjackGame, state *blackjack.Game) *GameOutcome {
+	return &GameOutcome{
+		RecordID:        game.ID,
+		Record:          blackjackHand{game: *game, state: state},
+		Message:         blackjackMessage(*game, state),
+		LossDescription: "Blackjack lost",
+		Pending:         state.Phase != blackjack.PhaseSettled,
+	}
+}
+
+func blackjackWinnings(game models.BlackjackGame) []models.Transaction {
+	if game.WinAmount <= 0 {
+		return nil
+	}
+	return []models.Transaction{{
+		Type:        "win",
+		Amount:      game.WinAmount,
+		Description: fmt.Sprintf("Blackjack paid %.2f on a total stake of %.2f", game.WinAmount, game.TotalStake),
+	}}
+}
+
+// payBlackjackGame sets the win of a settled hand, cut to the max win per bet.
+// Splits and doubles can take the payout well past the bet limits, so the cap
+// is applied to the hand as a whole rather than checked up front.
+func payBlackjackGame(game *models.BlackjackGame, state *blackjack.Game, settings models.GameSettings) {
+	game.WinAmount = state.Payout()
+	if settings.MaxWinPerBet > 0 && game.WinAmount > settings.MaxWinPerBet {
+		game.WinAmount = settings.MaxWinPerBet
+		game.Capped = true
+	}
+}
+
+// settleBlackjackGame books the final payout of a settled hand on the wallet.
+func settleBlackjackGame(tx *gorm.DB, wallet *models.Wallet, game *models.BlackjackGame, state *blackjack.Game) error {
+	settings, err := loadGameSettings()
+	if err != nil {
+		return err
+	}
+
+	payBlackjackGame(game, state, settings)
+	return settleGame(tx, wallet, "blackjack", blackjackOutcome(game, state), blackjackWinnings(*game))
+}
+
+// blackjackGame deals a hand from the player's current shoe. The shoe has its
+// own seed, so the deal doesn't use one up. A hand that isn't settled on the
+// deal stays open for the player's actions.
+type blackjackGame struct{}
+
+func (blackjackGame) Info() GameInfo {
+	return GameInfo{
+		Type:        "blackjack",
+		Name:        "Blackjack",
+		PlayPath:    "/deal",
+		HistoryPath: "/games",
+		HistoryKey:  "games",
+		SharedSeed:  true,
+	}
+}
+
+func (blackjackGame) ValidateBet(c *gin.Context, _ models.GameSettings) (*GameBet, error) {
+	var req DealBlackjackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, newGameError(http.StatusBadRequest, "Invalid request data: %s", err.Error())
+	}
+
+	settings, err := loadBlackjackSettings()
+	if err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Blackjack settings not found")
+	}
+
+	if !settings.IsActive {
+		return nil, newGameError(http.StatusServiceUnavailable, "Blackjack is currently unavailable")
+	}
+
+	return &GameBet{
+		Amount:      req.BetAmount,
+		MinBet:      settings.MinBetAmount,
+		MaxBet:      settings.MaxBetAmount,
+		Description: "Bet placed for blackjack",
+		Params:      settings,
+	}, nil
+}
+
+func (blackjackGame) Resolve(tx *gorm.DB, bet *GameBet, _ GameSeed) (*GameOutcome, error) {
+	settings := bet.Params.(models.BlackjackSettings)
+
+	// The wallet lock serialises the user's requests, so this check and the
+	// shoe can't be raced by a second deal.
+	var activeHands int64
+	tx.Model(&models.BlackjackGame{}).Where("user_id = ? AND status = ?", bet.UserID, "active").Count(&activeHands)
+	if activeHands > 0 {
+		return nil, newGameError(http.StatusConflict, "You already have an active blackjack game")
+	}
+
+	shoe, err := activeBlackjackShoe(tx, bet.UserID, settings)
+	if err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to prepare shoe")
+	}
+
+	state := blackjack.Deal(bet.Amount, blackjack.Rules{DealerHitsSoft17: settings.DealerHitsSoft17}, shoe.draw)
+	if err := shoe.save(tx); err != nil {
+		return nil, err
+	}
+
+	game := models.BlackjackGame{
+		UserID:     bet.UserID,
+		ShoeID:     shoe.model.ID,
+		BetAmount:  bet.Amount,
+		TotalStake: bet.Amount,
+		Status:     "active",
+	}
+	encodeBlackjackState(&game, state)
+	if state.Phase == blackjack.PhaseSettled {
+		payBlackjackGame(&game, state, bet.Settings)
+	}
+
+	if err := tx.Create(&game).Error; err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to create game")
+	}
+
+	return blackjackOutcome(&game, state), nil
+}
+
+func (blackjackGame) Settle(outcome *GameOutcome) []models.Transaction {
+	return blackjackWinnings(outcome.Record.(blackjackHand).game)
+}
+
+func (blackjackGame) Describe(outcome *GameOutcome) gin.H {
+	hand := outcome.Record.(blackjackHand)
+	return gin.H{
+		"game": blackjackGameData(hand.game, hand.state),
+	}
+}
+
+func (blackjackGame) History(_ *gin.Context, userID uint, limit int) ([]gin.H, error) {
+	var games []models.BlackjackGame
+	if err := config.DB.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&games).Error; err != nil {
+		return nil, err
+	}
+
+	var gameData []gin.H
+	for _, game := range games {
+		state, err := decodeBlackjackState(game)
+		if err != nil {
+			continue
+		}
+		gameData = append(gameData, blackjackGameData(game, state))
+	}
+	return gameData, nil
+}
+
+func (blackjackGame) Routes(group *gin.RouterGroup) {
+	group.POST("/hit", BlackjackHit)
+	group.POST("/stand", BlackjackStand)
+	group.POST("/double", BlackjackDouble)
+	group.POST("/split", BlackjackSplit)
+	group.POST("/insurance", BlackjackInsurance)
+	group.GET("/active", GetActiveBlackjackGame)
+	group.GET("/shoes", GetBlackjackShoes)
+	group.GET("/settings", GetBlackjackSettings)
+}
+
+// finishBlackjackAction stores the shoe position and the new state, settling
+// the game on the wallet when the last action ended it.
+func finishBlackjackAction(tx *gorm.DB, wallet *models.Wallet, game *models.BlackjackGame, state *blackjack.Game, shoe *blackjackShoe) error {
+	if err := shoe.save(tx); err != nil {
+		return err
+	}
+
+	encodeBlackjackState(game, state)
+	if state.Phase == blackjack.PhaseSettled {
+		if err := settleBlackjackGame(tx, wallet, game, state); err != nil {
+			return err
+		}
+	}
+	return tx.Save(game).Error
+}
+
+func BlackjackHit(c *gin.Context) {
+	playBlackjackAction(c, blackjack.ActionHit)
+}
+
+func BlackjackStand(c *gin.Context) {
+	playBlackjackAction(c, blackjack.ActionStand)
+}
+
+func BlackjackDouble(c *gin.Context) {
+	playBlackjackAction(c, blackjack.ActionDouble)
+}
+
+func BlackjackSplit(c *gin.Context) {
+	playBlackjackAction(c, blackjack.ActionSplit)
+}
+
+func BlackjackInsurance(c *gin.Context) {
+	var req BlackjackInsuranceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	action := blackjack.ActionNoInsurance
+	if *req.Take {
+		action = blackjack.ActionInsurance
+	}
+	applyBlackjackAction(c, req.GameID, action)
+}
+
+func playBlackjackAction(c *gin.Context, action string) {
+	var req BlackjackActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	applyBlackjackAction(c, req.GameID, action)
+}
+
+// applyBlackjackAction moves an active game on by one action. Any extra stake
+// for a double, split or insurance is debited in the same transaction as the
+// state change, so a short wallet leaves the game untouched.
+func applyBlackjackAction(c *gin.Context, gameID uint, action string) {
+	userID := c.GetUint("user_id")
+
+	tx := config.DB.Begin()
+
+	wallet, err := lockWallet(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Wallet not found",
+		})
+		return
+	}
+
+	var game models.BlackjackGame
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ? AND status = ?", gameID, userID, "active").
+		First(&game).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Active blackjack game not found",
+		})
+		return
+	}
+
+	state, err := decodeBlackjackState(game)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Blackjack game state is invalid",
+		})
+		return
+	}
+
+	cost, err := state.Cost(action)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	shoe, err := loadBlackjackShoe(tx, game.ShoeID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Blackjack shoe not found",
+		})
+		return
+	}
+
+	oldBalance := wallet.Balance
+	if cost > 0 {
+		if err := debitStake(tx, wallet, "blackjack", &GameOutcome{RecordID: game.ID}, cost, fmt.Sprintf("Blackjack %s stake", action)); err != nil {
+			tx.Rollback()
+			if errors.Is(err, errInsufficientBalance) {
+				c.JSON(http.StatusBadRequest, GameResponse{
+					Success: false,
+					Message: "Insufficient wallet balance",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, GameResponse{
+				Success: false,
+				Message: "Failed to deduct stake",
+			})
+			return
+		}
+		game.TotalStake += cost
+	}
+
+	if err := state.Apply(action, shoe.draw); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := finishBlackjackAction(tx, wallet, &game, state, shoe); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to update game",
+		})
+		return
+	}
+
+	tx.Commit()
+
+	if wallet.Balance != oldBalance || state.Phase == blackjack.PhaseSettled {
+		publishWallet(userID, wallet)
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: blackjackMessage(game, state),
+		Data: gin.H{
+			"game": blackjackGameData(game, state),
+			"wallet": gin.H{
+				"balance":  wallet.Balance,
+				"currency": wallet.Currency,
+			},
+		},
+	})
+}
+
+func blackjackMessage(game models.BlackjackGame, state *blackjack.Game) string {
+	switch state.Phase {
+	case blackjack.PhaseInsurance:
+		return "Dealer shows an ace - insurance?"
+	case blackjack.PhasePlayer:
+		return fmt.Sprintf("Playing hand %d", state.Active+1)
+	}
+	return fmt.Sprintf("Game settled - paid %.2f", game.WinAmount)
+}
+
+func cardNames(cards []blackjack.Card) []string {
+	names := []string{}
+	for _, card := range cards {
+		names = append(names, card.String())
+	}
+	return names
+}
+
+// blackjackGameData hides the dealer's hole card until the game is settled.
+func blackjackGameData(game models.BlackjackGame, state *blackjack.Game) gin.H {
+	var hands []gin.H
+	for _, hand := range state.Hands {
+		total, soft := blackjack.Total(hand.Cards)
+		hands = append(hands, gin.H{
+			"cards":   cardNames(hand.Cards),
+			"total":   total,
+			"soft":    soft,
+			"bet":     hand.Bet,
+			"doubled": hand.Doubled,
+			"split":   hand.Split,
+			"done":    hand.Done,
+			"result":  hand.Result,
+			"payout":  hand.Payout,
+		})
+	}
+
+	dealer := state.Dealer
+	if state.Phase != blackjack.PhaseSettled {
+		dealer = dealer[:1]
+	}
+	dealerTotal, _ := blackjack.Total(dealer)
+
+	return gin.H{
+		"id":          game.ID,
+		"status":      game.Status,
+		"phase":       state.Phase,
+		"bet_amount":  game.BetAmount,
+		"total_stake": game.TotalStake,
+		"win_amount":  game.WinAmount,
+		"capped":      game.Capped,
+		"hands":       hands,
+		"active_hand": state.Active,
+		"dealer": gin.H{
+			"cards":        cardNames(dealer),
+			"hidden_cards": len(state.Dealer) - len(dealer),
+			"total":        dealerTotal,
+		},
+		"insurance":         state.Insurance,
+		"insurance_payout":  state.InsurancePayout,
+		"available_actions": state.Allowed(),
+		"shoe_id":           game.ShoeID,
+		"created_at":        game.CreatedAt,
+	}
+}
+
+func GetActiveBlackjackGame(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var game models.BlackjackGame
+	if err := config.DB.Where("user_id = ? AND status = ?", userID, "active").First(&game).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "No active blackjack game",
+		})
+		return
+	}
+
+	state, err := decodeBlackjackState(game)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Blackjack game state is invalid",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Active blackjack game retrieved successfully",
+		Data: gin.H{
+			"game": blackjackGameData(game, state),
+		},
+	})
+}
+
+// GetBlackjackShoes lists the user's shoes. The server seed of a shoe is only
+// revealed once it has been retired, so its whole card order can be checked.
+func GetBlackjackShoes(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var shoes []models.BlackjackShoe
+	if err := config.DB.Preload("ServerSeed").Where("user_id = ?", userID).Order("id DESC").Limit(20).Find(&shoes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to retrieve blackjack shoes",
+		})
+		return
+	}
+
+	var shoeData []gin.H
+	for _, shoe := range shoes {
+		data := gin.H{
+			"id":               shoe.ID,
+			"decks":            shoe.Decks,
+			"status":           shoe.Status,
+			"cards_dealt":      shoe.Position,
+			"cut_card":         shoe.CutCard,
+			"server_seed_hash": shoe.ServerSeedHash,
+			"client_seed":      shoe.ClientSeed,
+			"nonce":            shoe.Nonce,
+			"created_at":       shoe.CreatedAt,
+		}
+		if shoe.Status == "retired" && shoe.ServerSeed != nil {
+			data["server_seed"] = shoe.ServerSeed.Seed
+		}
+		shoeData = append(shoeData, data)
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Blackjack shoes retrieved successfully",
+		Data: gin.H{
+			"shoes": shoeData,
+		},
+	})
+}
+
+func GetBlackjackSettings(c *gin.Context) {
+	settings, err := loadBlackjackSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Blackjack settings not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Blackjack settings retrieved successfully",
+		Data: gin.H{
+			"settings": gin.H{
+				"min_bet_amount":      settings.MinBetAmount,
+				"max_bet_amount":      settings.MaxBetAmount,
+				"decks":               settings.Decks,
+				"penetration":         settings.Penetration,
+				"dealer_hits_soft_17": settings.DealerHitsSoft17,
+				"blackjack_pays":      "3:2",
+				"max_hands":           blackjack.MaxHands,
+				"is_active":           settings.IsActive,
+			},
+		},
+	})
+}
@@ -2,26 +2,51 @@ package controllers
 
 import (
 	"casino_api_go/config"
+	"casino_api_go/curve"
+	"casino_api_go/fairness"
+	"casino_api_go/games/crash"
 	"casino_api_go/models"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type StartGameRequest struct {
-	BetAmount float64 `json:"bet_amount" binding:"required,gt=0"`
+	BetAmount     float64  `json:"bet_amount" binding:"required,gt=0"`
+	AutoCashoutAt *float64 `json:"auto_cashout_at" binding:"omitempty,gt=1"`
+}
+
+type UpdateAutoCashoutRequest struct {
+	AutoCashoutAt *float64 `json:"auto_cashout_at" binding:"omitempty,gt=1"`
 }
 
 type StopGameRequest struct {
 	GameID uint `json:"game_id" binding:"required"`
 }
 
+type PartialStopGameRequest struct {
+	GameID   uint    `json:"game_id" binding:"required"`
+	Fraction float64 `json:"fraction" binding:"required,gt=0,lt=1"`
+}
+
+const gameAlreadySettled = "Game already settled"
+
+// Error codes let clients tell a paused game apart from a rejected bet.
+const (
+	codeMaintenance  = "maintenance"
+	codeGameInactive = "game_inactive"
+)
+
 type GameResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
+	Code    string      `json:"code,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
@@ -31,138 +56,258 @@ var (
 	crashWorkerOnce sync.Once
 )
 
-func StartGame(c *gin.Context) {
-	userID := c.GetUint("user_id")
+// crashGame places bets on the shared crash round. A bet stays open until it
+// is cashed out or the round crashes, and is then settled by completeGame.
+type crashGame struct{}
+
+type crashBet struct {
+	game    models.Game
+	round   models.Round
+	jackpot *models.JackpotEntry
+	pool    *models.JackpotPool
+}
+
+func (crashGame) Info() GameInfo {
+	return GameInfo{
+		Type:        "crash",
+		Name:        "Crash",
+		Path:        "/api/casino",
+		PlayPath:    "/start",
+		HistoryPath: "/games",
+		HistoryKey:  "games",
+		SharedSeed:  true,
+	}
+}
 
+func (crashGame) ValidateBet(c *gin.Context, settings models.GameSettings) (*GameBet, error) {
 	var req StartGameRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, GameResponse{
-			Success: false,
-			Message: "Invalid request data: " + err.Error(),
-		})
-		return
+		return nil, newGameError(http.StatusBadRequest, "Invalid request data: %s", err.Error())
 	}
 
-	var user models.User
-	if err := config.DB.Preload("Wallet").First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, GameResponse{
-			Success: false,
-			Message: "User not found",
-		})
-		return
+	if !settings.IsActive {
+		return nil, &gameError{status: http.StatusServiceUnavailable, message: "Crash game is currently disabled", code: codeGameInactive}
 	}
 
-	if user.Status == "banned" {
-		c.JSON(http.StatusForbidden, GameResponse{
-			Success: false,
-			Message: "Account is banned",
-		})
-		return
+	bet := &GameBet{
+		Amount:      req.BetAmount,
+		MinBet:      settings.MinBetAmount,
+		MaxBet:      settings.MaxBetAmount,
+		Description: "Bet placed for casino game",
+		Params:      req,
+	}
+	if settings.MaxRoundLiability > 0 {
+		bet.Lock = &betPlacementMux
 	}
+	return bet, nil
+}
 
-	var settings models.GameSettings
-	if err := config.DB.Where("is_active = ?", true).First(&settings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, GameResponse{
-			Success: false,
-			Message: "Game settings not found",
-		})
-		return
+// Resolve joins the round that is taking bets. The crash point comes from the
+// round's seed; the jackpot draw takes a seed from the player's own pair.
+func (crashGame) Resolve(tx *gorm.DB, bet *GameBet, _ GameSeed) (*GameOutcome, error) {
+	req := bet.Params.(StartGameRequest)
+
+	var round models.Round
+	if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("status = ?", "betting").Order("id DESC").First(&round).Error; err != nil || !time.Now().Before(round.BettingEndsAt) {
+		return nil, newGameError(http.StatusConflict, "Betting is closed, please wait for the next round")
 	}
 
-	if req.BetAmount < settings.MinBetAmount || req.BetAmount > settings.MaxBetAmount {
-		c.JSON(http.StatusBadRequest, GameResponse{
-			Success: false,
-			Message: fmt.Sprintf("Bet amount must be between %.2f and %.2f", settings.MinBetAmount, settings.MaxBetAmount),
-		})
-		return
+	// The bet plays on the settings the round was opened with, not on any
+	// change made since.
+	snapshot, err := roundSettings(&round)
+	if err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Game settings not found")
 	}
 
-	if user.Wallet.Balance < req.BetAmount {
-		c.JSON(http.StatusBadRequest, GameResponse{
-			Success: false,
-			Message: "Insufficient wallet balance",
-		})
-		return
+	if req.AutoCashoutAt != nil && *req.AutoCashoutAt >= snapshot.MaxMultiplier {
+		return nil, newGameError(http.StatusBadRequest, "Auto cashout must be below the max multiplier of %.2fx", snapshot.MaxMultiplier)
 	}
 
-	tx := config.DB.Begin()
-	oldBalance := user.Wallet.Balance
-	user.Wallet.Balance -= req.BetAmount
+	if bet.Settings.MaxRoundLiability > 0 {
+		exposure := maxPayout(&models.Game{BetAmount: bet.Amount, AutoCashoutAt: req.AutoCashoutAt}, snapshot)
+		if openLiability(round.ID)+exposure > bet.Settings.MaxRoundLiability {
+			return nil, newGameError(http.StatusConflict, "This round has reached its liability limit, please wait for the next round")
+		}
+	}
 
-	if err := tx.Save(&user.Wallet).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, GameResponse{
-			Success: false,
-			Message: "Failed to deduct bet amount",
-		})
-		return
+	// The wallet lock serialises concurrent bets of the same user, so the
+	// one-bet-per-round check can't be raced.
+	var existingBets int64
+	tx.Model(&models.Game{}).Where("round_id = ? AND user_id = ?", round.ID, bet.UserID).Count(&existingBets)
+	if existingBets > 0 {
+		return nil, newGameError(http.StatusBadRequest, "You already have a bet in this round")
 	}
 
-	crashPoint := simulateGameCrash(settings)
 	game := models.Game{
-		UserID:      userID,
-		BetAmount:   req.BetAmount,
-		Multiplier:  1.0,
-		WinAmount:   0,
-		CrashPoint:  crashPoint,
-		Status:      "active",
-		IsCompleted: false,
+		UserID:         bet.UserID,
+		GameType:       "crash",
+		BetAmount:      bet.Amount,
+		Multiplier:     1.0,
+		WinAmount:      0,
+		CrashPoint:     round.CrashPoint,
+		Status:         "active",
+		IsCompleted:    false,
+		RoundID:        &round.ID,
+		AutoCashoutAt:  req.AutoCashoutAt,
+		ServerSeedID:   round.ServerSeedID,
+		ServerSeedHash: round.ServerSeedHash,
+		ClientSeed:     round.ClientSeed,
+
+		SettingsSnapshot: snapshotSettings(snapshot),
 	}
 
 	if err := tx.Create(&game).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, GameResponse{
-			Success: false,
-			Message: "Failed to create game",
-		})
-		return
+		return nil, newGameError(http.StatusInternalServerError, "Failed to create game")
 	}
 
-	transaction := models.Transaction{
-		UserID:      userID,
-		GameID:      &game.ID,
-		Type:        "bet",
-		Amount:      -req.BetAmount,
-		Balance:     user.Wallet.Balance,
-		Description: "Bet placed for casino game",
+	jackpot, pool, err := contributeToJackpot(tx, &game)
+	if err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to update jackpot")
 	}
 
-	if err := tx.Create(&transaction).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, GameResponse{
-			Success: false,
-			Message: "Failed to create transaction record",
-		})
-		return
+	message := "Bet placed for the upcoming round"
+	if jackpot != nil && jackpot.Won {
+		message = fmt.Sprintf("Bet placed for the upcoming round - jackpot won: %.2f", jackpot.WinAmount)
 	}
 
-	tx.Commit()
+	return &GameOutcome{
+		RecordID: game.ID,
+		GameID:   &game.ID,
+		Record: &crashBet{
+			game:    game,
+			round:   round,
+			jackpot: jackpot,
+			pool:    pool,
+		},
+		Message: message,
+		Pending: true,
+	}, nil
+}
+
+// Settle pays a jackpot the bet won when it was placed. The bet itself is
+// settled by completeGame.
+func (crashGame) Settle(outcome *GameOutcome) []models.Transaction {
+	placed := outcome.Record.(*crashBet)
+	if placed.jackpot == nil || !placed.jackpot.Won {
+		return nil
+	}
+	return []models.Transaction{{
+		Type:        "jackpot",
+		Amount:      placed.jackpot.WinAmount,
+		Description: fmt.Sprintf("Jackpot won on bet %d", placed.game.ID),
+	}}
+}
+
+// Committed hands the bet to the round scheduler, unless its round already
+// crashed, and announces it.
+func (crashGame) Committed(user models.User, outcome *GameOutcome) {
+	placed := outcome.Record.(*crashBet)
+	game := placed.game
 
+	// The round can crash between the commit and here. crashRound settles
+	// such a bet from the database, so it must not be registered against the
+	// crashed round. The status is read under activeGamesMux, which
+	// crashRound takes after marking the round crashed.
 	activeGamesMux.Lock()
-	activeGames[game.ID] = &game
+	var status string
+	config.DB.Model(&models.Round{}).Select("status").Where("id = ?", placed.round.ID).Scan(&status)
+	registered := status != "crashed"
+	if registered {
+		activeGames[game.ID] = &game
+	}
 	activeGamesMux.Unlock()
+	if registered {
+		scheduler.scheduleAutoCashout(&game)
+	}
 
-	crashWorkerOnce.Do(func() {
-		go startCrashWorker()
-	})
+	hub.broadcast("bet", gin.H{
+		"round_id":   placed.round.ID,
+		"game_id":    game.ID,
+		"username":   user.Username,
+		"bet_amount": game.BetAmount,
+	}, false)
+	publishJackpot(placed.jackpot, placed.pool, user.Username)
+}
 
-	c.JSON(http.StatusCreated, GameResponse{
-		Success: true,
-		Message: "Game started successfully",
-		Data: gin.H{
-			"game": gin.H{
-				"id":         game.ID,
-				"bet_amount": game.BetAmount,
-				"multiplier": game.Multiplier,
-				"status":     game.Status,
-			},
-			"wallet": gin.H{
-				"old_balance": oldBalance,
-				"new_balance": user.Wallet.Balance,
-				"currency":    user.Wallet.Currency,
-			},
+func (crashGame) Describe(outcome *GameOutcome) gin.H {
+	placed := outcome.Record.(*crashBet)
+	game := placed.game
+
+	data := gin.H{
+		"game": gin.H{
+			"id":              game.ID,
+			"round_id":        placed.round.ID,
+			"bet_amount":      game.BetAmount,
+			"multiplier":      game.Multiplier,
+			"auto_cashout_at": game.AutoCashoutAt,
+			"status":          game.Status,
 		},
-	})
+		"round": gin.H{
+			"id":              placed.round.ID,
+			"status":          placed.round.Status,
+			"betting_ends_at": placed.round.BettingEndsAt,
+		},
+		"fairness": gin.H{
+			"server_seed_hash": game.ServerSeedHash,
+			"client_seed":      game.ClientSeed,
+			"nonce":            game.Nonce,
+		},
+	}
+	if placed.jackpot != nil {
+		data["jackpot"] = jackpotEntryData(*placed.jackpot)
+	}
+	return data
+}
+
+// History lists the player's games of the crash family, crash bets and limbo
+// games alike, optionally filtered by ?game_type=.
+func (crashGame) History(c *gin.Context, userID uint, limit int) ([]gin.H, error) {
+	query := config.DB.Preload("Cashouts").Where("user_id = ?", userID)
+	if gameType := c.Query("game_type"); gameType != "" {
+		query = query.Where("game_type = ?", gameType)
+	}
+
+	var games []models.Game
+	if err := query.Order("created_at DESC").Limit(limit).Find(&games).Error; err != nil {
+		return nil, err
+	}
+
+	var gameData []gin.H
+	for _, game := range games {
+		var cashouts []gin.H
+		for _, cashout := range game.Cashouts {
+			cashouts = append(cashouts, cashoutData(cashout))
+		}
+
+		gameData = append(gameData, gin.H{
+			"id":               game.ID,
+			"game_type":        game.GameType,
+			"round_id":         game.RoundID,
+			"bet_amount":       game.BetAmount,
+			"remaining_stake":  game.BetAmount - game.CashedOutStake,
+			"multiplier":       game.Multiplier,
+			"auto_cashout_at":  game.AutoCashoutAt,
+			"win_amount":       game.WinAmount,
+			"status":           game.Status,
+			"is_completed":     game.IsCompleted,
+			"partial_cashouts": cashouts,
+			"created_at":       game.CreatedAt,
+		})
+	}
+	return gameData, nil
+}
+
+func (crashGame) Routes(group *gin.RouterGroup) {
+	group.POST("/stop", StopGame)
+	group.POST("/stop/partial", PartialStopGame)
+	group.GET("/settings", GetGameSettings)
+	group.GET("/active-games", GetActiveGamesStatus)
+	group.GET("/round", GetCurrentRound)
+	group.GET("/rounds", GetRoundHistory)
+	group.GET("/game/:id/crash-info", GetGameCrashInfo)
+	group.GET("/game/:id/jackpot", VerifyJackpotEntry)
+	group.PUT("/game/:id/auto-cashout", UpdateAutoCashout)
+	group.GET("/game/:id", GetGameStatus)
 }
 
 func StopGame(c *gin.Context) {
@@ -178,7 +323,7 @@ func StopGame(c *gin.Context) {
 	}
 
 	var game models.Game
-	if err := config.DB.First(&game, req.GameID).Error; err != nil {
+	if err := config.DB.Preload("Round").First(&game, req.GameID).Error; err != nil {
 		c.JSON(http.StatusNotFound, GameResponse{
 			Success: false,
 			Message: "Game not found",
@@ -194,7 +339,7 @@ func StopGame(c *gin.Context) {
 		return
 	}
 
-	if game.IsCompletedAtomically() {
+	if game.IsCompleted || game.Status != "active" {
 		c.JSON(http.StatusBadRequest, GameResponse{
 			Success: false,
 			Message: "Game is already completed",
@@ -202,12 +347,22 @@ func StopGame(c *gin.Context) {
 		return
 	}
 
+	if game.Round != nil && game.Round.Status == "betting" {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Round has not started yet",
+		})
+		return
+	}
+
 	activeGamesMux.Lock()
 	delete(activeGames, game.ID)
 	activeGamesMux.Unlock()
 	_, response := completeGame(&game, "manual_stop")
-	if response != nil {
+	if response != nil && response.Success {
 		c.JSON(http.StatusOK, *response)
+	} else if response != nil && response.Message == gameAlreadySettled {
+		c.JSON(http.StatusConflict, *response)
 	} else {
 		c.JSON(http.StatusInternalServerError, GameResponse{
 			Success: false,
@@ -216,12 +371,22 @@ func StopGame(c *gin.Context) {
 	}
 }
 
-func GetGameStatus(c *gin.Context) {
+// PartialStopGame cashes out a fraction of the remaining stake at the current
+// multiplier and leaves the rest of the bet riding.
+func PartialStopGame(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	gameID := c.Param("id")
+
+	var req PartialStopGameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
 
 	var game models.Game
-	if err := config.DB.First(&game, gameID).Error; err != nil {
+	if err := config.DB.Preload("Round").First(&game, req.GameID).Error; err != nil {
 		c.JSON(http.StatusNotFound, GameResponse{
 			Success: false,
 			Message: "Game not found",
@@ -237,73 +402,243 @@ func GetGameStatus(c *gin.Context) {
 		return
 	}
 
-	var settings models.GameSettings
-	if err := config.DB.Where("is_active = ?", true).First(&settings).Error; err != nil {
+	if game.IsCompleted || game.Status != "active" {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Game is already completed",
+		})
+		return
+	}
+
+	if game.Round == nil || game.Round.Status != "running" {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Round has not started yet",
+		})
+		return
+	}
+
+	settings, err := gameSettings(&game)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, GameResponse{
 			Success: false,
 			Message: "Game settings not found",
 		})
 		return
 	}
-	currentMultiplier := game.Multiplier
-	if !game.IsCompleted {
-		currentMultiplier = calculateCurrentMultiplier(game.CreatedAt, settings.MultiplierSpeed)
+
+	currentMultiplier := roundMultiplier(game.Round)
+	if currentMultiplier >= game.CrashPoint {
+		c.JSON(http.StatusConflict, GameResponse{
+			Success: false,
+			Message: "Round has already crashed",
+		})
+		return
+	}
+	if target := cashoutTarget(&game, settings); target != nil && *target <= currentMultiplier {
+		c.JSON(http.StatusConflict, GameResponse{
+			Success: false,
+			Message: "Auto cashout target already reached",
+		})
+		return
 	}
 
+	tx := config.DB.Begin()
+
+	var locked models.Game
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND status = ?", game.ID, "active").First(&locked).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusConflict, *alreadySettledResponse(game.ID))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to load game",
+		})
+		return
+	}
+
+	stake := (locked.BetAmount - locked.CashedOutStake) * req.Fraction
+	winAmount := stake * currentMultiplier
+
+	if err := tx.Model(&models.Game{}).Where("id = ?", locked.ID).Updates(map[string]interface{}{
+		"cashed_out_stake": locked.CashedOutStake + stake,
+		"win_amount":       locked.WinAmount + winAmount,
+	}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to update game",
+		})
+		return
+	}
+
+	wallet, err := lockWallet(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Wallet not found",
+		})
+		return
+	}
+
+	oldBalance := wallet.Balance
+	transaction, err := creditWallet(tx, wallet, winAmount, models.Transaction{
+		GameID:      &game.ID,
+		GameType:    game.GameType,
+		Type:        "win",
+		Reference:   gameReference(game.GameType, game.ID),
+		Description: fmt.Sprintf("Partial cashout of %.0f%% with multiplier %.2fx", req.Fraction*100, currentMultiplier),
+	})
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to update wallet",
+		})
+		return
+	}
+
+	cashout := models.GameCashout{
+		GameID:        game.ID,
+		UserID:        userID,
+		Fraction:      req.Fraction,
+		Stake:         stake,
+		Multiplier:    currentMultiplier,
+		WinAmount:     winAmount,
+		TransactionID: &transaction.ID,
+	}
+	if err := tx.Create(&cashout).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to record cashout",
+		})
+		return
+	}
+
+	tx.Commit()
+
+	remainingStake := locked.BetAmount - locked.CashedOutStake - stake
+
+	activeGamesMux.Lock()
+	active, ok := activeGames[game.ID]
+	if ok {
+		active.CashedOutStake = locked.CashedOutStake + stake
+		active.WinAmount = locked.WinAmount + winAmount
+	}
+	activeGamesMux.Unlock()
+
+	// A smaller stake needs a higher multiplier to reach the max win cap.
+	if ok {
+		scheduler.scheduleAutoCashout(active)
+	}
+
+	var user models.User
+	config.DB.Select("id", "username").First(&user, userID)
+
+	publishWallet(userID, wallet)
+	hub.broadcast("cashout", gin.H{
+		"round_id":   game.RoundID,
+		"game_id":    game.ID,
+		"username":   user.Username,
+		"multiplier": currentMultiplier,
+		"win_amount": winAmount,
+		"partial":    true,
+	}, false)
+
 	c.JSON(http.StatusOK, GameResponse{
 		Success: true,
-		Message: "Game status retrieved successfully",
+		Message: "Partial cashout successful",
 		Data: gin.H{
+			"cashout": cashoutData(cashout),
 			"game": gin.H{
-				"id":           game.ID,
-				"bet_amount":   game.BetAmount,
-				"multiplier":   currentMultiplier,
-				"win_amount":   game.WinAmount,
-				"status":       game.Status,
-				"is_completed": game.IsCompleted,
-				"created_at":   game.CreatedAt,
+				"id":               game.ID,
+				"round_id":         game.RoundID,
+				"bet_amount":       game.BetAmount,
+				"remaining_stake":  remainingStake,
+				"cashed_out_stake": locked.CashedOutStake + stake,
+				"win_amount":       locked.WinAmount + winAmount,
+				"status":           "active",
+			},
+			"wallet": gin.H{
+				"old_balance": oldBalance,
+				"new_balance": wallet.Balance,
+				"currency":    wallet.Currency,
 			},
 		},
 	})
 }
 
-func GetUserGames(c *gin.Context) {
+func cashoutData(cashout models.GameCashout) gin.H {
+	return gin.H{
+		"id":         cashout.ID,
+		"fraction":   cashout.Fraction,
+		"stake":      cashout.Stake,
+		"multiplier": cashout.Multiplier,
+		"win_amount": cashout.WinAmount,
+		"created_at": cashout.CreatedAt,
+	}
+}
+
+func GetGameStatus(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	gameID := c.Param("id")
 
-	var games []models.Game
-	if err := config.DB.Where("user_id = ?", userID).Order("created_at DESC").Limit(20).Find(&games).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, GameResponse{
+	var game models.Game
+	if err := config.DB.Preload("Round").Preload("Cashouts").First(&game, gameID).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
 			Success: false,
-			Message: "Failed to retrieve games",
+			Message: "Game not found",
 		})
 		return
 	}
 
-	var gameData []gin.H
-	for _, game := range games {
-		gameData = append(gameData, gin.H{
-			"id":           game.ID,
-			"bet_amount":   game.BetAmount,
-			"multiplier":   game.Multiplier,
-			"win_amount":   game.WinAmount,
-			"status":       game.Status,
-			"is_completed": game.IsCompleted,
-			"created_at":   game.CreatedAt,
+	if game.UserID != userID {
+		c.JSON(http.StatusForbidden, GameResponse{
+			Success: false,
+			Message: "Access denied",
 		})
+		return
+	}
+
+	currentMultiplier := game.Multiplier
+	if !game.IsCompleted {
+		currentMultiplier = roundMultiplier(game.Round)
+	}
+
+	var cashouts []gin.H
+	for _, cashout := range game.Cashouts {
+		cashouts = append(cashouts, cashoutData(cashout))
 	}
 
 	c.JSON(http.StatusOK, GameResponse{
 		Success: true,
-		Message: "Games retrieved successfully",
+		Message: "Game status retrieved successfully",
 		Data: gin.H{
-			"games": gameData,
+			"game": gin.H{
+				"id":               game.ID,
+				"game_type":        game.GameType,
+				"round_id":         game.RoundID,
+				"bet_amount":       game.BetAmount,
+				"remaining_stake":  game.BetAmount - game.CashedOutStake,
+				"multiplier":       currentMultiplier,
+				"auto_cashout_at":  game.AutoCashoutAt,
+				"win_amount":       game.WinAmount,
+				"status":           game.Status,
+				"is_completed":     game.IsCompleted,
+				"partial_cashouts": cashouts,
+				"created_at":       game.CreatedAt,
+			},
 		},
 	})
 }
 
 func GetGameSettings(c *gin.Context) {
-	var settings models.GameSettings
-	if err := config.DB.Where("is_active = ?", true).First(&settings).Error; err != nil {
+	settings, err := loadGameSettings()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, GameResponse{
 			Success: false,
 			Message: "Game settings not found",
@@ -316,226 +651,381 @@ func GetGameSettings(c *gin.Context) {
 		Message: "Game settings retrieved successfully",
 		Data: gin.H{
 			"settings": gin.H{
-				"min_bet_amount": settings.MinBetAmount,
-				"max_bet_amount": settings.MaxBetAmount,
-				"max_multiplier": settings.MaxMultiplier,
+				"min_bet_amount":   settings.MinBetAmount,
+				"max_bet_amount":   settings.MaxBetAmount,
+				"max_multiplier":   settings.MaxMultiplier,
+				"multiplier_speed": settings.MultiplierSpeed,
+				"curve":            settings.Curve,
+				"curve_points":     curvePointsData(settings),
+				"max_win_per_bet":  settings.MaxWinPerBet,
 			},
 		},
 	})
 }
 
-func calculateCurrentMultiplier(createdAt time.Time, speed float64) float64 {
-	elapsed := time.Since(createdAt).Seconds()
-	multiplier := 1.0 + (elapsed * speed)
-
-	if multiplier > 100.0 {
-		multiplier = 100.0
+func crashParams(settings models.GameSettings) fairness.Params {
+	return fairness.Params{
+		MaxMultiplier:      settings.MaxMultiplier,
+		HouseEdge:          settings.HouseEdge,
+		InstantCrashChance: settings.InstantCrashChance,
+		Distribution:       settings.Distribution,
+		Shape:              settings.DistributionShape,
 	}
+}
 
-	return multiplier
+// crashSettings is the part of the settings the simulator plays with.
+func crashSettings(settings models.GameSettings) crash.Settings {
+	return crash.Settings{
+		Params:       crashParams(settings),
+		Curve:        gameCurve(settings),
+		MaxWinPerBet: settings.MaxWinPerBet,
+	}
 }
 
-func simulateGameCrash(settings models.GameSettings) float64 {
-	crashPoint := settings.MaxMultiplier
+func UpdateAutoCashout(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	gameID := c.Param("id")
 
-	if crashPoint < 1.01 {
-		crashPoint = 1.01
+	var req UpdateAutoCashoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
 	}
 
-	return crashPoint
-}
+	var game models.Game
+	if err := config.DB.Preload("Round").First(&game, gameID).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Game not found",
+		})
+		return
+	}
 
-func completeGame(game *models.Game, stopReason string) (*gin.Context, *GameResponse) {
-	if !game.TryCompleteGame() {
-		return nil, &GameResponse{
+	if game.UserID != userID {
+		c.JSON(http.StatusForbidden, GameResponse{
 			Success: false,
-			Message: "Game already completed",
-		}
+			Message: "Access denied",
+		})
+		return
 	}
 
-	var user models.User
-	if err := config.DB.Preload("Wallet").First(&user, game.UserID).Error; err != nil {
-		return nil, &GameResponse{
+	if game.IsCompleted {
+		c.JSON(http.StatusBadRequest, GameResponse{
 			Success: false,
-			Message: "User not found",
+			Message: "Game is already completed",
+		})
+		return
+	}
+
+	settings, err := gameSettings(&game)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Game settings not found",
+		})
+		return
+	}
+
+	if req.AutoCashoutAt != nil {
+		currentMultiplier := roundMultiplier(game.Round)
+		if *req.AutoCashoutAt <= currentMultiplier {
+			c.JSON(http.StatusBadRequest, GameResponse{
+				Success: false,
+				Message: fmt.Sprintf("Auto cashout must be above the current multiplier of %.2fx", currentMultiplier),
+			})
+			return
+		}
+		if *req.AutoCashoutAt >= settings.MaxMultiplier {
+			c.JSON(http.StatusBadRequest, GameResponse{
+				Success: false,
+				Message: fmt.Sprintf("Auto cashout must be below the max multiplier of %.2fx", settings.MaxMultiplier),
+			})
+			return
 		}
 	}
 
-	var settings models.GameSettings
-	if err := config.DB.Where("is_active = ?", true).First(&settings).Error; err != nil {
+	result := config.DB.Model(&models.Game{}).
+		Where("id = ? AND status = ?", game.ID, "active").
+		Update("auto_cashout_at", req.AutoCashoutAt)
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, GameResponse{
+			Success: false,
+			Message: "Failed to update auto cashout, game may already be settled",
+		})
+		return
+	}
+
+	activeGamesMux.Lock()
+	activeGame, ok := activeGames[game.ID]
+	if ok {
+		activeGame.AutoCashoutAt = req.AutoCashoutAt
+	}
+	activeGamesMux.Unlock()
+	if ok {
+		scheduler.scheduleAutoCashout(activeGame)
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Auto cashout updated successfully",
+		Data: gin.H{
+			"game": gin.H{
+				"id":              game.ID,
+				"round_id":        game.RoundID,
+				"auto_cashout_at": req.AutoCashoutAt,
+				"status":          game.Status,
+			},
+		},
+	})
+}
+
+func gameCurve(settings models.GameSettings) curve.Curve {
+	points, _ := curve.ParsePoints(settings.CurvePoints)
+	growth, err := curve.New(settings.Curve, settings.MultiplierSpeed, points)
+	if err != nil {
+		growth, _ = curve.New(curve.Linear, settings.MultiplierSpeed, nil)
+	}
+	return growth
+}
+
+func curvePointsData(settings models.GameSettings) []curve.Point {
+	points, _ := curve.ParsePoints(settings.CurvePoints)
+	return points
+}
+
+func calculateCurrentMultiplier(startedAt time.Time, settings models.GameSettings) float64 {
+	return crash.Multiplier(gameCurve(settings), time.Since(startedAt).Seconds(), settings.MaxMultiplier)
+}
+
+// completeGame settles a game exactly once. The status change is a
+// conditional update on the active row, so when a manual stop, an auto cashout
+// and the crash race for the same game only the first one pays out and the
+// others get an "already settled" response.
+func completeGame(game *models.Game, stopReason string) (*gin.Context, *GameResponse) {
+	settings, err := gameSettings(game)
+	if err != nil {
 		return nil, &GameResponse{
 			Success: false,
 			Message: "Game settings not found",
 		}
 	}
 
-	currentMultiplier := calculateCurrentMultiplier(game.CreatedAt, settings.MultiplierSpeed)
+	var round models.Round
+	if game.RoundID != nil {
+		if err := config.DB.First(&round, *game.RoundID).Error; err != nil {
+			return nil, &GameResponse{
+				Success: false,
+				Message: "Round not found",
+			}
+		}
+	}
+
+	currentMultiplier := roundMultiplier(&round)
 	crashPoint := game.CrashPoint
 
+	// Games recovered after a restart are settled as if their round had run
+	// to its crash point.
+	if stopReason == "recovery" && currentMultiplier < crashPoint {
+		currentMultiplier = crashPoint
+	}
+
 	tx := config.DB.Begin()
-	game.Multiplier = currentMultiplier
-	game.IsCompleted = true
+
+	// Partial cashouts shrink the stake, so it is read from the locked row
+	// rather than from the caller's copy of the game.
+	var locked models.Game
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND status = ?", game.ID, "active").First(&locked).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, alreadySettledResponse(game.ID)
+		}
+		return nil, &GameResponse{
+			Success: false,
+			Message: "Failed to load game",
+		}
+	}
+	remainingStake := locked.BetAmount - locked.CashedOutStake
+
+	// A target that was already reached pays exactly the target, even if the
+	// worker or a manual stop only got to the game a little later. The max
+	// win cap acts as a target too.
+	currentMultiplier, won := crash.Settle(currentMultiplier, crashPoint, cashoutTarget(&locked, settings))
 
 	var winAmount float64
 	var gameStatus string
-	var transactionType string
 	var description string
 
-	if currentMultiplier < crashPoint {
-		winAmount = game.BetAmount * currentMultiplier
+	if won {
+		winAmount = remainingStake * currentMultiplier
 		gameStatus = "won"
-		transactionType = "win"
 		description = fmt.Sprintf("Game won with multiplier %.2fx", currentMultiplier)
-		user.Wallet.Balance += winAmount
 	} else {
 		winAmount = 0
 		gameStatus = "lost"
-		transactionType = "loss"
 		description = fmt.Sprintf("Game lost - crashed at multiplier %.2fx", crashPoint)
 	}
+	if locked.CashedOutStake > 0 {
+		description += fmt.Sprintf(" on remaining stake %.2f", remainingStake)
+	}
+	if stopReason == "recovery" {
+		description += " (recovered after restart)"
+	}
 
-	game.WinAmount = winAmount
-	game.Status = gameStatus
-
-	oldBalance := user.Wallet.Balance
-	if err := tx.Save(&user.Wallet).Error; err != nil {
+	result := tx.Model(&models.Game{}).
+		Where("id = ? AND status = ?", game.ID, "active").
+		Updates(map[string]interface{}{
+			"status":       gameStatus,
+			"multiplier":   currentMultiplier,
+			"win_amount":   locked.WinAmount + winAmount,
+			"is_completed": true,
+		})
+	if result.Error != nil {
 		tx.Rollback()
 		return nil, &GameResponse{
 			Success: false,
-			Message: "Failed to update wallet",
+			Message: "Failed to update game",
 		}
 	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, alreadySettledResponse(game.ID)
+	}
 
-	if err := tx.Save(&game).Error; err != nil {
+	wallet, err := lockWallet(tx, game.UserID)
+	if err != nil {
 		tx.Rollback()
 		return nil, &GameResponse{
 			Success: false,
-			Message: "Failed to update game",
+			Message: "Wallet not found",
 		}
 	}
 
-	transaction := models.Transaction{
-		UserID:      game.UserID,
-		GameID:      &game.ID,
-		Type:        transactionType,
-		Amount:      winAmount,
-		Balance:     user.Wallet.Balance,
-		Description: description,
+	oldBalance := wallet.Balance
+	outcome := &GameOutcome{RecordID: game.ID, GameID: &game.ID, LossDescription: description}
+	var entries []models.Transaction
+	if won {
+		entries = append(entries, models.Transaction{
+			Type:        "win",
+			Amount:      winAmount,
+			Description: description,
+		})
 	}
-
-	if err := tx.Create(&transaction).Error; err != nil {
+	if err := settleGame(tx, wallet, game.GameType, outcome, entries); err != nil {
 		tx.Rollback()
 		return nil, &GameResponse{
 			Success: false,
-			Message: "Failed to create transaction record",
+			Message: "Failed to update wallet",
 		}
 	}
 
-	tx.Commit()
+	if err := tx.Commit().Error; err != nil {
+		return nil, &GameResponse{
+			Success: false,
+			Message: "Failed to settle game",
+		}
+	}
+
+	game.Multiplier = currentMultiplier
+	game.CashedOutStake = locked.CashedOutStake
+	game.WinAmount = locked.WinAmount + winAmount
+	game.Status = gameStatus
+	game.IsCompleted = true
 
 	message := "Game won!"
 	if gameStatus == "lost" {
 		message = "Game lost - crashed!"
 	}
 
+	data := gin.H{
+		"game": gin.H{
+			"id":               game.ID,
+			"round_id":         game.RoundID,
+			"bet_amount":       game.BetAmount,
+			"remaining_stake":  remainingStake,
+			"cashed_out_stake": game.CashedOutStake,
+			"multiplier":       game.Multiplier,
+			"win_amount":       game.WinAmount,
+			"status":           game.Status,
+			"stop_reason":      stopReason,
+		},
+		"wallet": gin.H{
+			"old_balance": oldBalance,
+			"new_balance": wallet.Balance,
+			"currency":    wallet.Currency,
+		},
+	}
+	// A cashout can settle while the round is still climbing; its crash point
+	// would tell other bets in the round where to set their auto cashout.
+	if crashPointRevealed(game, &round) {
+		data["game"].(gin.H)["crash_point"] = crashPoint
+	}
+
+	hub.sendToUser(game.UserID, "settlement", data)
+	publishWallet(game.UserID, wallet)
+	if gameStatus == "won" {
+		var user models.User
+		config.DB.Select("id", "username").First(&user, game.UserID)
+		hub.broadcast("cashout", gin.H{
+			"round_id":   game.RoundID,
+			"game_id":    game.ID,
+			"username":   user.Username,
+			"multiplier": game.Multiplier,
+			"win_amount": game.WinAmount,
+		}, false)
+	}
+
 	return nil, &GameResponse{
 		Success: true,
 		Message: message,
-		Data: gin.H{
-			"game": gin.H{
-				"id":          game.ID,
-				"bet_amount":  game.BetAmount,
-				"multiplier":  game.Multiplier,
-				"win_amount":  game.WinAmount,
-				"status":      game.Status,
-				"crash_point": crashPoint,
-				"stop_reason": stopReason,
-			},
-			"wallet": gin.H{
-				"old_balance": oldBalance,
-				"new_balance": user.Wallet.Balance,
-				"currency":    user.Wallet.Currency,
-			},
-		},
+		Data:    data,
 	}
 }
 
-func startCrashWorker() {
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		activeGamesMux.RLock()
-		gameIDs := make([]uint, 0, len(activeGames))
-		for gameID := range activeGames {
-			gameIDs = append(gameIDs, gameID)
-		}
-		activeGamesMux.RUnlock()
-
-		for _, gameID := range gameIDs {
-			var game models.Game
-			if err := config.DB.First(&game, gameID).Error; err != nil {
-				activeGamesMux.Lock()
-				delete(activeGames, gameID)
-				activeGamesMux.Unlock()
-				continue
-			}
+// alreadySettledResponse is returned to every caller that lost the race to
+// settle a game, with the outcome the winner recorded.
+func alreadySettledResponse(gameID uint) *GameResponse {
+	var settled models.Game
+	config.DB.First(&settled, gameID)
 
-			if game.IsCompletedAtomically() {
-				activeGamesMux.Lock()
-				delete(activeGames, gameID)
-				activeGamesMux.Unlock()
-				continue
-			}
-
-			var settings models.GameSettings
-			if err := config.DB.Where("is_active = ?", true).First(&settings).Error; err != nil {
-				continue
-			}
-
-			currentMultiplier := calculateCurrentMultiplier(game.CreatedAt, settings.MultiplierSpeed)
-			crashPoint := game.CrashPoint
-			if currentMultiplier >= crashPoint {
-				activeGamesMux.Lock()
-				delete(activeGames, gameID)
-				activeGamesMux.Unlock()
-
-				go func(g models.Game) {
-					_, response := completeGame(&g, "auto_crash")
-					if response != nil && response.Success {
-						println("Game auto-crashed: ID", g.ID, "at multiplier", currentMultiplier)
-					}
-				}(game)
-			}
-		}
+	return &GameResponse{
+		Success: false,
+		Message: gameAlreadySettled,
+		Data: gin.H{
+			"game": gin.H{
+				"id":         settled.ID,
+				"round_id":   settled.RoundID,
+				"multiplier": settled.Multiplier,
+				"win_amount": settled.WinAmount,
+				"status":     settled.Status,
+			},
+		},
 	}
 }
 
 func GetActiveGamesStatus(c *gin.Context) {
 	activeGamesMux.RLock()
-	defer activeGamesMux.RUnlock()
+	games := make([]models.Game, 0, len(activeGames))
+	for _, game := range activeGames {
+		games = append(games, *game)
+	}
+	activeGamesMux.RUnlock()
 
 	var activeGamesData []gin.H
-	for gameID := range activeGames {
-		var freshGame models.Game
-		if err := config.DB.First(&freshGame, gameID).Error; err != nil {
-			continue
-		}
-
-		var settings models.GameSettings
-		if err := config.DB.Where("is_active = ?", true).First(&settings).Error; err != nil {
-			continue
-		}
-
-		currentMultiplier := calculateCurrentMultiplier(freshGame.CreatedAt, settings.MultiplierSpeed)
+	for _, game := range games {
+		round := scheduler.roundByID(game.RoundID)
+		currentMultiplier := roundMultiplier(round)
 
 		activeGamesData = append(activeGamesData, gin.H{
-			"game_id":            gameID,
-			"user_id":            freshGame.UserID,
-			"bet_amount":         freshGame.BetAmount,
+			"game_id":            game.ID,
+			"round_id":           game.RoundID,
+			"user_id":            game.UserID,
+			"bet_amount":         game.BetAmount,
 			"current_multiplier": currentMultiplier,
-			"created_at":         freshGame.CreatedAt,
-			"elapsed_time":       time.Since(freshGame.CreatedAt).Seconds(),
+			"created_at":         game.CreatedAt,
+			"elapsed_time":       roundElapsed(round),
 		})
 	}
 
@@ -554,7 +1044,7 @@ func GetGameCrashInfo(c *gin.Context) {
 	gameID := c.Param("id")
 
 	var game models.Game
-	if err := config.DB.First(&game, gameID).Error; err != nil {
+	if err := config.DB.Preload("Round").First(&game, gameID).Error; err != nil {
 		c.JSON(http.StatusNotFound, GameResponse{
 			Success: false,
 			Message: "Game not found",
@@ -570,8 +1060,8 @@ func GetGameCrashInfo(c *gin.Context) {
 		return
 	}
 
-	var settings models.GameSettings
-	if err := config.DB.Where("is_active = ?", true).First(&settings).Error; err != nil {
+	settings, err := gameSettings(&game)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, GameResponse{
 			Success: false,
 			Message: "Game settings not found",
@@ -579,29 +1069,41 @@ func GetGameCrashInfo(c *gin.Context) {
 		return
 	}
 
-	currentMultiplier := calculateCurrentMultiplier(game.CreatedAt, settings.MultiplierSpeed)
-	crashPoint := game.CrashPoint
-
-	isActive := !game.IsCompleted
-	timeToCrash := 0.0
-	if isActive && currentMultiplier < crashPoint {
-		timeToCrash = (crashPoint - currentMultiplier) / settings.MultiplierSpeed
+	currentMultiplier := roundMultiplier(game.Round)
+
+	gameData := gin.H{
+		"id":                 game.ID,
+		"round_id":           game.RoundID,
+		"bet_amount":         game.BetAmount,
+		"current_multiplier": currentMultiplier,
+		"is_active":          !game.IsCompleted,
+		"elapsed_time":       roundElapsed(game.Round),
+		"multiplier_speed":   settings.MultiplierSpeed,
+		"curve":              settings.Curve,
+	}
+	// The crash point, and the time left until it, are only shown once the
+	// round has crashed. Before that they would let the player set an auto
+	// cashout just below it.
+	if crashPointRevealed(&game, game.Round) {
+		gameData["crash_point"] = game.CrashPoint
+		gameData["time_to_crash"] = 0.0
 	}
 
 	c.JSON(http.StatusOK, GameResponse{
 		Success: true,
 		Message: "Game crash info retrieved successfully",
 		Data: gin.H{
-			"game": gin.H{
-				"id":                 game.ID,
-				"bet_amount":         game.BetAmount,
-				"current_multiplier": currentMultiplier,
-				"crash_point":        crashPoint,
-				"is_active":          isActive,
-				"time_to_crash":      timeToCrash,
-				"elapsed_time":       time.Since(game.CreatedAt).Seconds(),
-				"multiplier_speed":   settings.MultiplierSpeed,
-			},
+			"game": gameData,
 		},
 	})
 }
+
+// crashPointRevealed reports whether a game's crash point may be shown: for
+// a crash bet once its round has crashed, for a game without a round, such as
+// limbo, once it is settled.
+func crashPointRevealed(game *models.Game, round *models.Round) bool {
+	if game.RoundID == nil {
+		return game.IsCompleted
+	}
+	return round != nil && round.Status == "crashed"
+}
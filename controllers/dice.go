@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/models"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RollDiceRequest struct {
+	BetAmount float64 `json:"bet_amount" binding:"required,gt=0"`
+	Target    float64 `json:"target" binding:"required,gt=0,lt=100"`
+	Direction string  `json:"direction" binding:"required,oneof=over under"`
+}
+
+func loadDiceSettings() (models.DiceSettings, error) {
+	var settings models.DiceSettings
+	err := config.DB.Order("id DESC").First(&settings).Error
+	return settings, err
+}
+
+type diceGame struct{}
+
+type diceBet struct {
+	target     float64
+	direction  string
+	winChance  float64
+	multiplier float64
+}
+
+func (diceGame) Info() GameInfo {
+	return GameInfo{
+		Type:        "dice",
+		Name:        "Dice",
+		PlayPath:    "/roll",
+		HistoryPath: "/rolls",
+		HistoryKey:  "rolls",
+		Event:       "dice_roll",
+	}
+}
+
+// ValidateBet applies the dice settings rather than the crash game's, since
+// dice has its own bet limits and switch.
+func (diceGame) ValidateBet(c *gin.Context, _ models.GameSettings) (*GameBet, error) {
+	var req RollDiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, newGameError(http.StatusBadRequest, "Invalid request data: %s", err.Error())
+	}
+
+	settings, err := loadDiceSettings()
+	if err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Dice settings not found")
+	}
+
+	if !settings.IsActive {
+		return nil, newGameError(http.StatusServiceUnavailable, "Dice is currently unavailable")
+	}
+
+	req.Target = math.Round(req.Target*100) / 100
+	winChance := fairness.DiceWinChance(req.Target, req.Direction)
+	if winChance < settings.MinWinChance || winChance > settings.MaxWinChance {
+		return nil, newGameError(http.StatusBadRequest, "Win chance must be between %.2f%% and %.2f%%", settings.MinWinChance, settings.MaxWinChance)
+	}
+	multiplier := fairness.DiceMultiplier(winChance, settings.HouseEdge)
+
+	return &GameBet{
+		Amount:      req.BetAmount,
+		MinBet:      settings.MinBetAmount,
+		MaxBet:      settings.MaxBetAmount,
+		MaxPayout:   req.BetAmount * multiplier,
+		Description: "Bet placed for dice roll",
+		Params: diceBet{
+			target:     req.Target,
+			direction:  req.Direction,
+			winChance:  winChance,
+			multiplier: multiplier,
+		},
+	}, nil
+}
+
+func (diceGame) Resolve(tx *gorm.DB, bet *GameBet, seed GameSeed) (*GameOutcome, error) {
+	params := bet.Params.(diceBet)
+
+	roll := fairness.DiceRoll(fairness.GameHash(seed.ServerSeed.Seed, seed.ClientSeed, seed.Nonce))
+	diceRoll := models.DiceRoll{
+		UserID:         bet.UserID,
+		BetAmount:      bet.Amount,
+		Target:         params.target,
+		Direction:      params.direction,
+		Roll:           roll,
+		WinChance:      params.winChance,
+		Multiplier:     params.multiplier,
+		Status:         "lost",
+		ServerSeedID:   &seed.ServerSeed.ID,
+		ServerSeedHash: seed.ServerSeed.Hash,
+		ClientSeed:     seed.ClientSeed,
+		Nonce:          seed.Nonce,
+	}
+	if fairness.DiceWins(roll, params.target, params.direction) {
+		diceRoll.Status = "won"
+		diceRoll.WinAmount = bet.Amount * params.multiplier
+	}
+
+	if err := tx.Create(&diceRoll).Error; err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to record dice roll")
+	}
+	diceRoll.ServerSeed = seed.ServerSeed
+
+	message := "Dice won!"
+	if diceRoll.Status == "lost" {
+		message = "Dice lost"
+	}
+
+	return &GameOutcome{
+		RecordID:        diceRoll.ID,
+		Record:          diceRoll,
+		Message:         message,
+		LossDescription: fmt.Sprintf("Dice lost - rolled %.2f, needed %s %.2f", roll, params.direction, params.target),
+	}, nil
+}
+
+func (diceGame) Settle(outcome *GameOutcome) []models.Transaction {
+	diceRoll := outcome.Record.(models.DiceRoll)
+	if diceRoll.Status != "won" {
+		return nil
+	}
+	return []models.Transaction{{
+		Type:        "win",
+		Amount:      diceRoll.WinAmount,
+		Description: fmt.Sprintf("Dice won with multiplier %.4fx - rolled %.2f", diceRoll.Multiplier, diceRoll.Roll),
+	}}
+}
+
+func (diceGame) Describe(outcome *GameOutcome) gin.H {
+	return gin.H{
+		"roll": diceRollData(outcome.Record.(models.DiceRoll)),
+	}
+}
+
+func (diceGame) History(_ *gin.Context, userID uint, limit int) ([]gin.H, error) {
+	var rolls []models.DiceRoll
+	if err := config.DB.Preload("ServerSeed").Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&rolls).Error; err != nil {
+		return nil, err
+	}
+
+	var rollData []gin.H
+	for _, roll := range rolls {
+		rollData = append(rollData, diceRollData(roll))
+	}
+	return rollData, nil
+}
+
+func (diceGame) Routes(group *gin.RouterGroup) {
+	group.GET("/settings", GetDiceSettings)
+	group.GET("/roll/:id/verify", VerifyDiceRoll)
+}
+
+func diceRollData(roll models.DiceRoll) gin.H {
+	data := gin.H{
+		"id":         roll.ID,
+		"bet_amount": roll.BetAmount,
+		"target":     roll.Target,
+		"direction":  roll.Direction,
+		"roll":       roll.Roll,
+		"win_chance": roll.WinChance,
+		"multiplier": roll.Multiplier,
+		"win_amount": roll.WinAmount,
+		"status":     roll.Status,
+		"created_at": roll.CreatedAt,
+	}
+
+	fairnessData := gin.H{
+		"server_seed_hash": roll.ServerSeedHash,
+		"client_seed":      roll.ClientSeed,
+		"nonce":            roll.Nonce,
+	}
+	if roll.ServerSeed != nil {
+		fairnessData["server_seed"] = roll.ServerSeed.Seed
+	}
+	data["fairness"] = fairnessData
+
+	return data
+}
+
+func GetDiceSettings(c *gin.Context) {
+	settings, err := loadDiceSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Dice settings not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Dice settings retrieved successfully",
+		Data: gin.H{
+			"settings": gin.H{
+				"min_bet_amount": settings.MinBetAmount,
+				"max_bet_amount": settings.MaxBetAmount,
+				"house_edge":     settings.HouseEdge,
+				"min_win_chance": settings.MinWinChance,
+				"max_win_chance": settings.MaxWinChance,
+				"is_active":      settings.IsActive,
+			},
+		},
+	})
+}
+
+func VerifyDiceRoll(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	rollID := c.Param("id")
+
+	var roll models.DiceRoll
+	if err := config.DB.Preload("ServerSeed").First(&roll, rollID).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Dice roll not found",
+		})
+		return
+	}
+
+	if roll.UserID != userID {
+		c.JSON(http.StatusForbidden, GameResponse{
+			Success: false,
+			Message: "Access denied",
+		})
+		return
+	}
+
+	if roll.ServerSeed == nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Dice roll was not played with a provably fair seed",
+		})
+		return
+	}
+
+	serverSeed := roll.ServerSeed
+	gameHash := fairness.GameHash(serverSeed.Seed, roll.ClientSeed, roll.Nonce)
+	computedRoll := fairness.DiceRoll(gameHash)
+	seedMatches := fairness.HashSeed(serverSeed.Seed) == roll.ServerSeedHash
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Dice roll verification retrieved successfully",
+		Data: gin.H{
+			"roll": gin.H{
+				"id":        roll.ID,
+				"target":    roll.Target,
+				"direction": roll.Direction,
+				"roll":      roll.Roll,
+				"status":    roll.Status,
+			},
+			"fairness": gin.H{
+				"server_seed":      serverSeed.Seed,
+				"server_seed_hash": roll.ServerSeedHash,
+				"client_seed":      roll.ClientSeed,
+				"nonce":            roll.Nonce,
+				"game_hash":        gameHash,
+				"computed_roll":    computedRoll,
+				"verified":         seedMatches && computedRoll == roll.Roll,
+			},
+		},
+	})
+}
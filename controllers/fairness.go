@@ -0,0 +1,348 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/models"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	seedPoolBatch    = 200
+	seedPoolLowWater = 50
+
+	unclaimedSeed = "user_id IS NULL AND round_id IS NULL AND keno_draw_id IS NULL"
+)
+
+type UpdateClientSeedRequest struct {
+	ClientSeed string `json:"client_seed" binding:"required,min=1,max=64"`
+}
+
+// ensureSeedPool tops up the server seed pool with fresh seeds. Every seed is
+// drawn on its own and committed to only by its own hash, so revealing one
+// seed says nothing about any other seed in the pool, whoever claimed it. It
+// runs outside of the bet transaction so the new seeds are visible to it.
+func ensureSeedPool() error {
+	var available int64
+	if err := config.DB.Model(&models.ServerSeed{}).Where(unclaimedSeed).Count(&available).Error; err != nil {
+		return err
+	}
+	if available >= seedPoolLowWater {
+		return nil
+	}
+
+	seeds := make([]models.ServerSeed, 0, seedPoolBatch)
+	for i := 0; i < seedPoolBatch; i++ {
+		seed, err := fairness.GenerateSeed()
+		if err != nil {
+			return err
+		}
+		seeds = append(seeds, models.ServerSeed{
+			Seed: seed,
+			Hash: fairness.HashSeed(seed),
+		})
+	}
+
+	return config.DB.CreateInBatches(&seeds, seedPoolBatch).Error
+}
+
+// takeServerSeed claims the next unassigned seed of the pool for a user, a
+// round or a keno draw. owner is the column that records the claim.
+func takeServerSeed(tx *gorm.DB, owner string, ownerID uint) (*models.ServerSeed, error) {
+	var lastID uint
+	for attempt := 0; attempt < 10; attempt++ {
+		var seed models.ServerSeed
+		if err := tx.Where(unclaimedSeed+" AND id > ?", lastID).Order("id ASC").First(&seed).Error; err != nil {
+			return nil, err
+		}
+
+		result := tx.Model(&models.ServerSeed{}).
+			Where("id = ? AND "+unclaimedSeed, seed.ID).
+			Update(owner, ownerID)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			switch owner {
+			case "round_id":
+				seed.RoundID = &ownerID
+			case "keno_draw_id":
+				seed.KenoDrawID = &ownerID
+			default:
+				seed.UserID = &ownerID
+			}
+			return &seed, nil
+		}
+		lastID = seed.ID
+	}
+
+	return nil, errors.New("no server seed available")
+}
+
+func loadUserSeed(tx *gorm.DB, userID uint) (*models.UserSeed, error) {
+	var userSeed models.UserSeed
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("ServerSeed").Where("user_id = ?", userID).First(&userSeed).Error
+	if err == nil {
+		return &userSeed, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	clientSeed, err := fairness.GenerateSeed()
+	if err != nil {
+		return nil, err
+	}
+
+	serverSeed, err := takeServerSeed(tx, "user_id", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	userSeed = models.UserSeed{
+		UserID:       userID,
+		ClientSeed:   clientSeed[:32],
+		Nonce:        0,
+		ServerSeedID: serverSeed.ID,
+		ServerSeed:   serverSeed,
+	}
+	if err := tx.Create(&userSeed).Error; err != nil {
+		return nil, err
+	}
+
+	return &userSeed, nil
+}
+
+// nextGameSeed consumes the server seed the user was committed to, bumps the
+// nonce and commits the user to a fresh seed for the following bet.
+func nextGameSeed(tx *gorm.DB, userID uint) (*models.ServerSeed, string, uint64, error) {
+	userSeed, err := loadUserSeed(tx, userID)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	serverSeed := userSeed.ServerSeed
+	now := time.Now()
+	if err := tx.Model(serverSeed).Update("used_at", now).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	next, err := takeServerSeed(tx, "user_id", userID)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	clientSeed := userSeed.ClientSeed
+	nonce := userSeed.Nonce
+
+	userSeed.Nonce++
+	userSeed.ServerSeedID = next.ID
+	userSeed.ServerSeed = nil
+	if err := tx.Save(userSeed).Error; err != nil {
+		return nil, "", 0, err
+	}
+
+	return serverSeed, clientSeed, nonce, nil
+}
+
+func GetFairnessSeeds(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := ensureSeedPool(); err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to prepare server seeds",
+		})
+		return
+	}
+
+	tx := config.DB.Begin()
+	userSeed, err := loadUserSeed(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to load fairness seeds",
+		})
+		return
+	}
+	tx.Commit()
+
+	var retired []models.ServerSeed
+	if err := config.DB.Where("user_id = ? AND retired_at IS NOT NULL", userID).Order("retired_at DESC").Find(&retired).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to load fairness seeds",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Fairness seeds retrieved successfully",
+		Data: gin.H{
+			"seeds": gin.H{
+				"client_seed":           userSeed.ClientSeed,
+				"nonce":                 userSeed.Nonce,
+				"next_server_seed_hash": userSeed.ServerSeed.Hash,
+			},
+			"retired_server_seeds": retiredSeedsData(retired),
+		},
+	})
+}
+
+// retiredSeedsData lists the seeds a user was committed to that were replaced
+// before they were played, so the old commitments can be checked.
+func retiredSeedsData(seeds []models.ServerSeed) []gin.H {
+	data := make([]gin.H, 0, len(seeds))
+	for _, seed := range seeds {
+		data = append(data, gin.H{
+			"server_seed":      seed.Seed,
+			"server_seed_hash": seed.Hash,
+			"retired_at":       seed.RetiredAt,
+		})
+	}
+	return data
+}
+
+func UpdateClientSeed(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req UpdateClientSeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if err := ensureSeedPool(); err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to prepare server seeds",
+		})
+		return
+	}
+
+	tx := config.DB.Begin()
+	userSeed, err := loadUserSeed(tx, userID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to load fairness seeds",
+		})
+		return
+	}
+
+	userSeed.ClientSeed = req.ClientSeed
+	if err := tx.Model(userSeed).Update("client_seed", req.ClientSeed).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to update client seed",
+		})
+		return
+	}
+	tx.Commit()
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Client seed updated successfully",
+		Data: gin.H{
+			"seeds": gin.H{
+				"client_seed":           userSeed.ClientSeed,
+				"nonce":                 userSeed.Nonce,
+				"next_server_seed_hash": userSeed.ServerSeed.Hash,
+			},
+		},
+	})
+}
+
+func VerifyGame(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	gameID := c.Param("id")
+
+	var game models.Game
+	if err := config.DB.Preload("ServerSeed").Preload("Round").First(&game, gameID).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Game not found",
+		})
+		return
+	}
+
+	if game.UserID != userID {
+		c.JSON(http.StatusForbidden, GameResponse{
+			Success: false,
+			Message: "Access denied",
+		})
+		return
+	}
+
+	if game.ServerSeed == nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Game was not played with a provably fair seed",
+		})
+		return
+	}
+
+	if game.Status == "active" || (game.Round != nil && game.Round.Status != "crashed") {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Server seed is revealed after the round has crashed",
+			Data: gin.H{
+				"fairness": gin.H{
+					"server_seed_hash": game.ServerSeedHash,
+					"client_seed":      game.ClientSeed,
+					"nonce":            game.Nonce,
+				},
+			},
+		})
+		return
+	}
+
+	settings, err := gameSettings(&game)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Game settings not found",
+		})
+		return
+	}
+
+	serverSeed := game.ServerSeed
+	gameHash := fairness.GameHash(serverSeed.Seed, game.ClientSeed, game.Nonce)
+	computedCrashPoint := fairness.CrashPoint(gameHash, crashParams(settings))
+	seedMatches := fairness.HashSeed(serverSeed.Seed) == game.ServerSeedHash
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Game verification retrieved successfully",
+		Data: gin.H{
+			"game": gin.H{
+				"id":          game.ID,
+				"round_id":    game.RoundID,
+				"status":      game.Status,
+				"crash_point": game.CrashPoint,
+			},
+			"fairness": gin.H{
+				"server_seed":          serverSeed.Seed,
+				"server_seed_hash":     game.ServerSeedHash,
+				"client_seed":          game.ClientSeed,
+				"nonce":                game.Nonce,
+				"game_hash":            gameHash,
+				"computed_crash_point": computedCrashPoint,
+				"verified":             seedMatches && computedCrashPoint == game.CrashPoint,
+			},
+		},
+	})
+}
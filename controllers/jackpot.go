@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxJackpotWins = 50
+
+func lockJackpotPool(tx *gorm.DB) (*models.JackpotPool, error) {
+	var pool models.JackpotPool
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id DESC").First(&pool).Error; err != nil {
+		return nil, err
+	}
+	return &pool, nil
+}
+
+// contributeToJackpot moves the pool's share of a crash bet into the jackpot
+// and draws the jackpot for the bet, inside the bet's transaction. The draw
+// uses the player's own seed pair, like an instant game, so its server seed
+// can be revealed right away without telling anything about the round. A win
+// takes the whole pool, which the crash provider pays out with the bet, and
+// restarts the pool from its seed amount. It returns a nil entry when the
+// jackpot is off or the bet is too small.
+func contributeToJackpot(tx *gorm.DB, game *models.Game) (*models.JackpotEntry, *models.JackpotPool, error) {
+	pool, err := lockJackpotPool(tx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	if !pool.IsActive || pool.ContributionRate <= 0 || game.BetAmount < pool.MinBetAmount {
+		return nil, pool, nil
+	}
+
+	serverSeed, clientSeed, nonce, err := nextGameSeed(tx, game.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contribution := game.BetAmount * pool.ContributionRate / 100
+	pool.Balance += contribution
+	pool.TotalContributed += contribution
+
+	roll := fairness.JackpotRoll(fairness.GameHash(serverSeed.Seed, clientSeed, nonce))
+	entry := models.JackpotEntry{
+		GameID:         game.ID,
+		UserID:         game.UserID,
+		Contribution:   contribution,
+		PoolBalance:    pool.Balance,
+		TriggerChance:  pool.TriggerChance,
+		Roll:           roll,
+		ServerSeedID:   serverSeed.ID,
+		ServerSeedHash: serverSeed.Hash,
+		ClientSeed:     clientSeed,
+		Nonce:          nonce,
+	}
+
+	if fairness.JackpotWins(roll, pool.TriggerChance) {
+		entry.Won = true
+		entry.WinAmount = pool.Balance
+
+		now := time.Now()
+		pool.TotalPaid += entry.WinAmount
+		pool.Balance = pool.SeedAmount
+		pool.TotalSeeded += pool.SeedAmount
+		pool.LastWonAt = &now
+	}
+
+	if err := tx.Save(pool).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return nil, nil, err
+	}
+
+	entry.ServerSeed = serverSeed
+	return &entry, pool, nil
+}
+
+// publishJackpot pushes the new pool value to live clients after a bet, and
+// announces a win.
+func publishJackpot(entry *models.JackpotEntry, pool *models.JackpotPool, username string) {
+	if entry == nil || pool == nil {
+		return
+	}
+
+	if entry.Won {
+		hub.broadcast("jackpot_won", gin.H{
+			"game_id":  entry.GameID,
+			"username": username,
+			"amount":   entry.WinAmount,
+			"balance":  pool.Balance,
+		}, false)
+		return
+	}
+	hub.broadcast("jackpot", gin.H{"balance": pool.Balance}, true)
+}
+
+func jackpotData(pool models.JackpotPool) gin.H {
+	return gin.H{
+		"balance":           pool.Balance,
+		"contribution_rate": pool.ContributionRate,
+		"trigger_chance":    pool.TriggerChance,
+		"min_bet_amount":    pool.MinBetAmount,
+		"is_active":         pool.IsActive,
+		"last_won_at":       pool.LastWonAt,
+	}
+}
+
+func jackpotEntryData(entry models.JackpotEntry) gin.H {
+	data := gin.H{
+		"game_id":        entry.GameID,
+		"contribution":   entry.Contribution,
+		"pool_balance":   entry.PoolBalance,
+		"trigger_chance": entry.TriggerChance,
+		"roll":           entry.Roll,
+		"won":            entry.Won,
+		"win_amount":     entry.WinAmount,
+		"created_at":     entry.CreatedAt,
+	}
+
+	fairnessData := gin.H{
+		"server_seed_hash": entry.ServerSeedHash,
+		"client_seed":      entry.ClientSeed,
+		"nonce":            entry.Nonce,
+	}
+	if entry.ServerSeed != nil {
+		fairnessData["server_seed"] = entry.ServerSeed.Seed
+	}
+	data["fairness"] = fairnessData
+
+	return data
+}
+
+func jackpotWinData(entry models.JackpotEntry) gin.H {
+	data := gin.H{
+		"game_id":    entry.GameID,
+		"amount":     entry.WinAmount,
+		"roll":       entry.Roll,
+		"created_at": entry.CreatedAt,
+	}
+	if entry.User != nil {
+		data["username"] = entry.User.Username
+	}
+	return data
+}
+
+func recentJackpotWins(limit int) ([]gin.H, error) {
+	var entries []models.JackpotEntry
+	if err := config.DB.Preload("User").Where("won = ?", true).Order("id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	var wins []gin.H
+	for _, entry := range entries {
+		wins = append(wins, jackpotWinData(entry))
+	}
+	return wins, nil
+}
+
+// GetJackpot shows the live jackpot value. It needs no login.
+func GetJackpot(c *gin.Context) {
+	var pool models.JackpotPool
+	if err := config.DB.Order("id DESC").First(&pool).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Jackpot not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Jackpot retrieved successfully",
+		Data: gin.H{
+			"jackpot": jackpotData(pool),
+		},
+	})
+}
+
+func GetJackpotWins(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > maxJackpotWins {
+		limit = 10
+	}
+
+	wins, err := recentJackpotWins(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to retrieve jackpot wins",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Jackpot wins retrieved successfully",
+		Data: gin.H{
+			"wins": wins,
+		},
+	})
+}
+
+// VerifyJackpotEntry recomputes the jackpot draw of one of the player's
+// crash bets from the revealed seeds.
+func VerifyJackpotEntry(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var entry models.JackpotEntry
+	if err := config.DB.Preload("ServerSeed").Where("game_id = ?", c.Param("id")).First(&entry).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Game did not take part in the jackpot",
+		})
+		return
+	}
+
+	if entry.UserID != userID {
+		c.JSON(http.StatusForbidden, GameResponse{
+			Success: false,
+			Message: "Access denied",
+		})
+		return
+	}
+
+	if entry.ServerSeed == nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Server seed not found",
+		})
+		return
+	}
+
+	serverSeed := entry.ServerSeed
+	gameHash := fairness.GameHash(serverSeed.Seed, entry.ClientSeed, entry.Nonce)
+	computedRoll := fairness.JackpotRoll(gameHash)
+	computedWin := fairness.JackpotWins(computedRoll, entry.TriggerChance)
+	seedMatches := fairness.HashSeed(serverSeed.Seed) == entry.ServerSeedHash
+
+	data := jackpotEntryData(entry)
+	fairnessData := data["fairness"].(gin.H)
+	fairnessData["game_hash"] = gameHash
+	fairnessData["computed_roll"] = computedRoll
+	fairnessData["computed_won"] = computedWin
+	fairnessData["verified"] = seedMatches && computedRoll == entry.Roll && computedWin == entry.Won
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Jackpot draw verification retrieved successfully",
+		Data: gin.H{
+			"jackpot": data,
+		},
+	})
+}
@@ -0,0 +1,626 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/games/keno"
+	"casino_api_go/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// kenoTicketCutoff closes ticket sales shortly before a draw so a ticket
+	// is never racing the draw itself.
+	kenoTicketCutoff  = 2 * time.Second
+	kenoSettleBatch   = 200
+	kenoRetryInterval = 5 * time.Second
+
+	defaultKenoClientSeed = "casino_api_go:keno"
+)
+
+var kenoWorkerOnce sync.Once
+
+type BuyKenoTicketRequest struct {
+	Picks     []int   `json:"picks" binding:"required,min=1,max=10"`
+	BetAmount float64 `json:"bet_amount" binding:"required,gt=0"`
+}
+
+func kenoClientSeed() string {
+	if seed := os.Getenv("KENO_CLIENT_SEED"); seed != "" {
+		return seed
+	}
+	return defaultKenoClientSeed
+}
+
+func loadKenoSettings() (models.KenoSettings, error) {
+	var settings models.KenoSettings
+	err := config.DB.Order("id DESC").First(&settings).Error
+	return settings, err
+}
+
+func decodeKenoPaytable(raw string) (keno.Paytable, error) {
+	var paytable keno.Paytable
+	if err := json.Unmarshal([]byte(raw), &paytable); err != nil {
+		return nil, err
+	}
+	return paytable, paytable.Validate()
+}
+
+// StartKenoScheduler starts the goroutine that opens, draws and settles keno
+// draws on a fixed schedule.
+func StartKenoScheduler() {
+	kenoWorkerOnce.Do(func() {
+		go runKenoDraws()
+	})
+}
+
+// runKenoDraws works through the draws one at a time. A draw left open or
+// half settled by a restart is picked up again on the first pass.
+func runKenoDraws() {
+	for {
+		draw, err := nextKenoDraw()
+		if err != nil {
+			log.Printf("Failed to prepare keno draw: %v", err)
+			time.Sleep(kenoRetryInterval)
+			continue
+		}
+		if draw == nil {
+			time.Sleep(kenoRetryInterval)
+			continue
+		}
+
+		if wait := time.Until(draw.DrawAt); draw.Status == "open" && wait > 0 {
+			time.Sleep(wait)
+		}
+
+		if err := performKenoDraw(draw); err != nil {
+			log.Printf("Failed to settle keno draw %d: %v", draw.ID, err)
+			time.Sleep(kenoRetryInterval)
+		}
+	}
+}
+
+// nextKenoDraw returns the oldest draw that still has to be drawn or settled,
+// opening a new one when there is none. It returns nil while keno is switched
+// off.
+func nextKenoDraw() (*models.KenoDraw, error) {
+	var draw models.KenoDraw
+	err := config.DB.Preload("ServerSeed").Where("status IN ?", []string{"open", "drawn"}).Order("id ASC").First(&draw).Error
+	if err == nil {
+		return &draw, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	settings, err := loadKenoSettings()
+	if err != nil {
+		return nil, err
+	}
+	if !settings.IsActive {
+		return nil, nil
+	}
+	return openKenoDraw(settings)
+}
+
+func openKenoDraw(settings models.KenoSettings) (*models.KenoDraw, error) {
+	if err := ensureSeedPool(); err != nil {
+		return nil, err
+	}
+
+	tx := config.DB.Begin()
+	draw := models.KenoDraw{
+		Status:     "open",
+		DrawAt:     time.Now().Add(time.Duration(settings.DrawInterval) * time.Second),
+		ClientSeed: kenoClientSeed(),
+	}
+	if err := tx.Create(&draw).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	serverSeed, err := takeServerSeed(tx, "keno_draw_id", draw.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := tx.Model(serverSeed).Update("used_at", now).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	draw.ServerSeedID = &serverSeed.ID
+	draw.ServerSeedHash = serverSeed.Hash
+	if err := tx.Save(&draw).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+
+	draw.ServerSeed = serverSeed
+	hub.broadcast("keno_draw_open", kenoDrawData(draw), false)
+	return &draw, nil
+}
+
+// performKenoDraw closes ticket sales, draws the numbers and settles every
+// ticket of the draw in batches.
+func performKenoDraw(draw *models.KenoDraw) error {
+	if draw.ServerSeed == nil {
+		return errors.New("draw has no server seed")
+	}
+	numbers := fairness.KenoDraw(draw.ServerSeed.Seed, draw.ClientSeed, 0)
+
+	if draw.Status == "open" {
+		// Ticket purchases hold a shared lock on the draw row, so this waits for
+		// any purchase in flight and every sold ticket is seen by the
+		// settlement below.
+		now := time.Now()
+		if err := config.DB.Model(&models.KenoDraw{}).
+			Where("id = ? AND status = ?", draw.ID, "open").
+			Updates(map[string]interface{}{"status": "drawn", "numbers": encodeTiles(numbers), "drawn_at": now}).Error; err != nil {
+			return err
+		}
+		draw.Status = "drawn"
+		draw.Numbers = encodeTiles(numbers)
+		draw.DrawnAt = &now
+	}
+
+	for {
+		settled, err := settleKenoBatch(draw.ID, numbers)
+		if err != nil {
+			return err
+		}
+		if settled < kenoSettleBatch {
+			break
+		}
+	}
+
+	var totals struct {
+		TotalTickets int64
+		TotalWagered float64
+		TotalPayout  float64
+	}
+	if err := config.DB.Model(&models.KenoTicket{}).
+		Select("COUNT(*) AS total_tickets, COALESCE(SUM(bet_amount), 0) AS total_wagered, COALESCE(SUM(win_amount), 0) AS total_payout").
+		Where("draw_id = ?", draw.ID).
+		Scan(&totals).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(draw).Updates(map[string]interface{}{
+		"status":        "settled",
+		"settled_at":    now,
+		"total_tickets": totals.TotalTickets,
+		"total_wagered": totals.TotalWagered,
+		"total_payout":  totals.TotalPayout,
+	}).Error; err != nil {
+		return err
+	}
+	draw.Status = "settled"
+	draw.SettledAt = &now
+	draw.TotalTickets = totals.TotalTickets
+	draw.TotalWagered = totals.TotalWagered
+	draw.TotalPayout = totals.TotalPayout
+
+	hub.broadcast("keno_draw", kenoDrawData(*draw), false)
+	return nil
+}
+
+// settleKenoBatch settles up to kenoSettleBatch pending tickets in one
+// transaction and returns how many it settled. Wallets are locked in user id
+// order so concurrent batches can't deadlock on them.
+func settleKenoBatch(drawID uint, numbers []int) (int, error) {
+	settings, err := loadGameSettings()
+	if err != nil {
+		return 0, err
+	}
+
+	tx := config.DB.Begin()
+
+	var tickets []models.KenoTicket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("draw_id = ? AND status = ?", drawID, "pending").
+		Order("user_id ASC, id ASC").
+		Limit(kenoSettleBatch).
+		Find(&tickets).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	wallets := make(map[uint]*models.Wallet)
+	for i := range tickets {
+		ticket := &tickets[i]
+
+		wallet, ok := wallets[ticket.UserID]
+		if !ok {
+			wallet, err = lockWallet(tx, ticket.UserID)
+			if err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+			wallets[ticket.UserID] = wallet
+		}
+
+		var payouts []float64
+		json.Unmarshal([]byte(ticket.Payouts), &payouts)
+		ticket.Hits = keno.Hits(decodeTiles(ticket.Picks), numbers)
+		if ticket.Hits < len(payouts) {
+			ticket.Multiplier = payouts[ticket.Hits]
+		}
+		ticket.WinAmount = ticket.BetAmount * ticket.Multiplier
+		if settings.MaxWinPerBet > 0 && ticket.WinAmount > settings.MaxWinPerBet {
+			ticket.WinAmount = settings.MaxWinPerBet
+			ticket.Capped = true
+		}
+
+		outcome := &GameOutcome{
+			RecordID:        ticket.ID,
+			LossDescription: fmt.Sprintf("Keno lost - %d of %d hit in draw %d", ticket.Hits, len(payouts)-1, drawID),
+		}
+		var entries []models.Transaction
+		ticket.Status = "lost"
+		if ticket.WinAmount > 0 {
+			ticket.Status = "won"
+			entries = append(entries, models.Transaction{
+				Type:        "win",
+				Amount:      ticket.WinAmount,
+				Description: fmt.Sprintf("Keno won %.2fx - %d of %d hit in draw %d", ticket.Multiplier, ticket.Hits, len(payouts)-1, drawID),
+			})
+		}
+
+		if err := settleGame(tx, wallet, "keno", outcome, entries); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := tx.Save(ticket).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	for userID, wallet := range wallets {
+		publishWallet(userID, wallet)
+	}
+	return len(tickets), nil
+}
+
+// kenoDrawData reveals the server seed and numbers only once the draw has
+// happened.
+func kenoDrawData(draw models.KenoDraw) gin.H {
+	data := gin.H{
+		"id":               draw.ID,
+		"status":           draw.Status,
+		"draw_at":          draw.DrawAt,
+		"server_seed_hash": draw.ServerSeedHash,
+		"client_seed":      draw.ClientSeed,
+	}
+
+	if draw.Status != "open" {
+		data["numbers"] = decodeTiles(draw.Numbers)
+		data["drawn_at"] = draw.DrawnAt
+		if draw.ServerSeed != nil {
+			data["server_seed"] = draw.ServerSeed.Seed
+		}
+	}
+	if draw.Status == "settled" {
+		data["settled_at"] = draw.SettledAt
+		data["total_tickets"] = draw.TotalTickets
+		data["total_wagered"] = draw.TotalWagered
+		data["total_payout"] = draw.TotalPayout
+	}
+
+	return data
+}
+
+func kenoTicketData(ticket models.KenoTicket) gin.H {
+	var payouts []float64
+	json.Unmarshal([]byte(ticket.Payouts), &payouts)
+
+	return gin.H{
+		"id":         ticket.ID,
+		"draw_id":    ticket.DrawID,
+		"picks":      decodeTiles(ticket.Picks),
+		"payouts":    payouts,
+		"bet_amount": ticket.BetAmount,
+		"status":     ticket.Status,
+		"hits":       ticket.Hits,
+		"multiplier": ticket.Multiplier,
+		"win_amount": ticket.WinAmount,
+		"capped":     ticket.Capped,
+		"created_at": ticket.CreatedAt,
+	}
+}
+
+// kenoGame sells tickets for the open draw. The draw has its own seed, so a
+// ticket doesn't use one up, and the scheduler settles every ticket when the
+// draw happens.
+type kenoGame struct{}
+
+type kenoBet struct {
+	picks   []int
+	payouts []float64
+}
+
+type kenoPurchase struct {
+	ticket models.KenoTicket
+	draw   models.KenoDraw
+}
+
+func (kenoGame) Info() GameInfo {
+	return GameInfo{
+		Type:        "keno",
+		Name:        "Keno",
+		PlayPath:    "/tickets",
+		HistoryPath: "/tickets",
+		HistoryKey:  "tickets",
+		SharedSeed:  true,
+	}
+}
+
+func (kenoGame) ValidateBet(c *gin.Context, _ models.GameSettings) (*GameBet, error) {
+	var req BuyKenoTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, newGameError(http.StatusBadRequest, "Invalid request data: %s", err.Error())
+	}
+
+	if err := keno.ValidatePicks(req.Picks); err != nil {
+		return nil, newGameError(http.StatusBadRequest, "Invalid picks: %s", err.Error())
+	}
+
+	settings, err := loadKenoSettings()
+	if err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Keno settings not found")
+	}
+
+	if !settings.IsActive {
+		return nil, newGameError(http.StatusServiceUnavailable, "Keno is currently unavailable")
+	}
+
+	paytable, err := decodeKenoPaytable(settings.Paytable)
+	if err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Keno paytable is invalid")
+	}
+
+	picks := append([]int(nil), req.Picks...)
+	sort.Ints(picks)
+
+	return &GameBet{
+		Amount: req.BetAmount,
+		MinBet: settings.MinBetAmount,
+		MaxBet: settings.MaxBetAmount,
+		Params: kenoBet{
+			picks:   picks,
+			payouts: paytable[len(picks)],
+		},
+	}, nil
+}
+
+func (kenoGame) Resolve(tx *gorm.DB, bet *GameBet, _ GameSeed) (*GameOutcome, error) {
+	params := bet.Params.(kenoBet)
+
+	var draw models.KenoDraw
+	if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("status = ? AND draw_at > ?", "open", time.Now().Add(kenoTicketCutoff)).
+		Order("id ASC").
+		First(&draw).Error; err != nil {
+		return nil, newGameError(http.StatusConflict, "No keno draw is open for tickets, try again shortly")
+	}
+
+	payouts, _ := json.Marshal(params.payouts)
+	ticket := models.KenoTicket{
+		DrawID:    draw.ID,
+		UserID:    bet.UserID,
+		Picks:     encodeTiles(params.picks),
+		Payouts:   string(payouts),
+		BetAmount: bet.Amount,
+		Status:    "pending",
+	}
+	if err := tx.Create(&ticket).Error; err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to create ticket")
+	}
+
+	// The draw is only known now, so the bet is described here.
+	bet.Description = fmt.Sprintf("Keno ticket with %d picks for draw %d", len(params.picks), draw.ID)
+
+	return &GameOutcome{
+		RecordID: ticket.ID,
+		Record:   kenoPurchase{ticket: ticket, draw: draw},
+		Message:  fmt.Sprintf("Ticket bought for draw %d", draw.ID),
+		Pending:  true,
+	}, nil
+}
+
+func (kenoGame) Settle(outcome *GameOutcome) []models.Transaction {
+	return nil
+}
+
+func (kenoGame) Describe(outcome *GameOutcome) gin.H {
+	purchase := outcome.Record.(kenoPurchase)
+	return gin.H{
+		"ticket": kenoTicketData(purchase.ticket),
+		"draw":   kenoDrawData(purchase.draw),
+	}
+}
+
+// History lists the player's tickets, optionally only those with the status
+// given in ?status.
+func (kenoGame) History(c *gin.Context, userID uint, limit int) ([]gin.H, error) {
+	query := config.DB.Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var tickets []models.KenoTicket
+	if err := query.Order("id DESC").Limit(limit).Find(&tickets).Error; err != nil {
+		return nil, err
+	}
+
+	var ticketData []gin.H
+	for _, ticket := range tickets {
+		ticketData = append(ticketData, kenoTicketData(ticket))
+	}
+	return ticketData, nil
+}
+
+func (kenoGame) Routes(group *gin.RouterGroup) {
+	group.GET("/draws/current", GetCurrentKenoDraw)
+	group.GET("/draws", GetKenoDraws)
+	group.GET("/draws/:id/verify", VerifyKenoDraw)
+	group.GET("/settings", GetKenoSettings)
+}
+
+func GetCurrentKenoDraw(c *gin.Context) {
+	var draw models.KenoDraw
+	if err := config.DB.Where("status = ?", "open").Order("id ASC").First(&draw).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "No keno draw is open",
+		})
+		return
+	}
+
+	var tickets int64
+	config.DB.Model(&models.KenoTicket{}).Where("draw_id = ?", draw.ID).Count(&tickets)
+
+	data := kenoDrawData(draw)
+	data["total_tickets"] = tickets
+	data["tickets_close_at"] = draw.DrawAt.Add(-kenoTicketCutoff)
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Current keno draw retrieved successfully",
+		Data: gin.H{
+			"draw": data,
+		},
+	})
+}
+
+func GetKenoDraws(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var draws []models.KenoDraw
+	if err := config.DB.Preload("ServerSeed").Where("status = ?", "settled").Order("id DESC").Limit(limit).Find(&draws).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to retrieve keno draws",
+		})
+		return
+	}
+
+	var drawData []gin.H
+	for _, draw := range draws {
+		drawData = append(drawData, kenoDrawData(draw))
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Keno draws retrieved successfully",
+		Data: gin.H{
+			"draws": drawData,
+		},
+	})
+}
+
+func VerifyKenoDraw(c *gin.Context) {
+	var draw models.KenoDraw
+	if err := config.DB.Preload("ServerSeed").First(&draw, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Keno draw not found",
+		})
+		return
+	}
+
+	if draw.Status == "open" || draw.ServerSeed == nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Keno draw has not happened yet",
+		})
+		return
+	}
+
+	serverSeed := draw.ServerSeed
+	numbers := fairness.KenoDraw(serverSeed.Seed, draw.ClientSeed, 0)
+	seedMatches := fairness.HashSeed(serverSeed.Seed) == draw.ServerSeedHash
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Keno draw verification retrieved successfully",
+		Data: gin.H{
+			"draw": kenoDrawData(draw),
+			"fairness": gin.H{
+				"server_seed":      serverSeed.Seed,
+				"server_seed_hash": draw.ServerSeedHash,
+				"client_seed":      draw.ClientSeed,
+				"computed_numbers": numbers,
+				"verified":         seedMatches && encodeTiles(numbers) == draw.Numbers,
+			},
+		},
+	})
+}
+
+func kenoPaytableData(paytable keno.Paytable) []gin.H {
+	var rows []gin.H
+	for picks := 1; picks <= keno.MaxPicks; picks++ {
+		rows = append(rows, gin.H{
+			"picks":           picks,
+			"payouts":         paytable[picks],
+			"theoretical_rtp": paytable.RTP(picks) * 100,
+		})
+	}
+	return rows
+}
+
+func GetKenoSettings(c *gin.Context) {
+	settings, err := loadKenoSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Keno settings not found",
+		})
+		return
+	}
+
+	paytable, _ := decodeKenoPaytable(settings.Paytable)
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Keno settings retrieved successfully",
+		Data: gin.H{
+			"settings": gin.H{
+				"min_bet_amount": settings.MinBetAmount,
+				"max_bet_amount": settings.MaxBetAmount,
+				"draw_interval":  settings.DrawInterval,
+				"numbers":        keno.Numbers,
+				"draw_size":      keno.DrawSize,
+				"max_picks":      keno.MaxPicks,
+				"paytable":       kenoPaytableData(paytable),
+				"is_active":      settings.IsActive,
+			},
+		},
+	})
+}
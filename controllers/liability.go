@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"casino_api_go/games/crash"
+	"casino_api_go/models"
+	"sync"
+)
+
+// betPlacementMux serialises the liability check and registration of new bets
+// so concurrent bets can't overshoot the round limit together.
+var betPlacementMux sync.Mutex
+
+// maxWinMultiplier returns the multiplier at which the remaining stake of a
+// game reaches the max win per bet, or nil when no cap is configured.
+func maxWinMultiplier(game *models.Game, settings models.GameSettings) *float64 {
+	return crash.MaxWinMultiplier(settings.MaxWinPerBet, game.WinAmount, game.BetAmount-game.CashedOutStake)
+}
+
+// cashoutTarget is the multiplier the server cashes a game out at: the
+// player's auto cashout or the max win cap, whichever comes first.
+func cashoutTarget(game *models.Game, settings models.GameSettings) *float64 {
+	return crash.CashoutTarget(game.AutoCashoutAt, maxWinMultiplier(game, settings))
+}
+
+// maxPayout is the most the house can still owe on a game.
+func maxPayout(game *models.Game, settings models.GameSettings) float64 {
+	multiplier := settings.MaxMultiplier
+	if target := cashoutTarget(game, settings); target != nil && *target < multiplier {
+		multiplier = *target
+	}
+	return (game.BetAmount - game.CashedOutStake) * multiplier
+}
+
+// openLiability sums the max payout of the registered active games, limited
+// to one round when roundID is non-zero. Each game is valued with the
+// settings it was started with.
+func openLiability(roundID uint) float64 {
+	activeGamesMux.RLock()
+	defer activeGamesMux.RUnlock()
+
+	var total float64
+	for _, game := range activeGames {
+		if roundID != 0 && (game.RoundID == nil || *game.RoundID != roundID) {
+			continue
+		}
+		settings, err := gameSettings(game)
+		if err != nil {
+			continue
+		}
+		total += maxPayout(game, settings)
+	}
+	return total
+}
@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/models"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PlayLimboRequest struct {
+	BetAmount float64 `json:"bet_amount" binding:"required,gt=0"`
+	Target    float64 `json:"target" binding:"required,gt=1"`
+}
+
+// limboGame draws a single crash point for the player's target and settles
+// the bet in the same request. It shares the crash game's settings, so the
+// result follows the same distribution and house edge.
+type limboGame struct{}
+
+func (limboGame) Info() GameInfo {
+	return GameInfo{
+		Type:        "limbo",
+		Name:        "Limbo",
+		PlayPath:    "/play",
+		HistoryPath: "/games",
+		HistoryKey:  "games",
+		Event:       "settlement",
+	}
+}
+
+func (limboGame) ValidateBet(c *gin.Context, settings models.GameSettings) (*GameBet, error) {
+	if !settings.IsActive {
+		return nil, &gameError{status: http.StatusServiceUnavailable, message: "Limbo is currently disabled", code: codeGameInactive}
+	}
+
+	var req PlayLimboRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, newGameError(http.StatusBadRequest, "Invalid request data: %s", err.Error())
+	}
+
+	target := math.Floor(req.Target*100) / 100
+	if target <= 1 || target > settings.MaxMultiplier {
+		return nil, newGameError(http.StatusBadRequest, "Target must be between 1.01x and %.2fx", settings.MaxMultiplier)
+	}
+
+	return &GameBet{
+		Amount:      req.BetAmount,
+		MinBet:      settings.MinBetAmount,
+		MaxBet:      settings.MaxBetAmount,
+		MaxPayout:   req.BetAmount * target,
+		Description: "Bet placed for limbo game",
+		Params:      target,
+	}, nil
+}
+
+func (limboGame) Resolve(tx *gorm.DB, bet *GameBet, seed GameSeed) (*GameOutcome, error) {
+	target := bet.Params.(float64)
+	result := fairness.CrashPoint(fairness.GameHash(seed.ServerSeed.Seed, seed.ClientSeed, seed.Nonce), crashParams(bet.Settings))
+
+	game := models.Game{
+		UserID:         bet.UserID,
+		GameType:       "limbo",
+		BetAmount:      bet.Amount,
+		Multiplier:     target,
+		CrashPoint:     result,
+		Status:         "lost",
+		IsCompleted:    true,
+		AutoCashoutAt:  &target,
+		ServerSeedID:   &seed.ServerSeed.ID,
+		ServerSeedHash: seed.ServerSeed.Hash,
+		ClientSeed:     seed.ClientSeed,
+		Nonce:          seed.Nonce,
+
+		SettingsSnapshot: snapshotSettings(bet.Settings),
+	}
+	if result >= target {
+		game.Status = "won"
+		game.WinAmount = bet.Amount * target
+	}
+
+	if err := tx.Create(&game).Error; err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to create game")
+	}
+	game.ServerSeed = seed.ServerSeed
+
+	message := "Game won!"
+	if game.Status == "lost" {
+		message = "Game lost"
+	}
+
+	return &GameOutcome{
+		RecordID:        game.ID,
+		GameID:          &game.ID,
+		Record:          game,
+		Message:         message,
+		LossDescription: fmt.Sprintf("Limbo lost - result %.2fx below target %.2fx", result, target),
+	}, nil
+}
+
+func (limboGame) Settle(outcome *GameOutcome) []models.Transaction {
+	game := outcome.Record.(models.Game)
+	if game.Status != "won" {
+		return nil
+	}
+	return []models.Transaction{{
+		Type:        "win",
+		Amount:      game.WinAmount,
+		Description: fmt.Sprintf("Limbo won with multiplier %.2fx - result %.2fx", game.Multiplier, game.CrashPoint),
+	}}
+}
+
+func (limboGame) Describe(outcome *GameOutcome) gin.H {
+	data := limboGameData(outcome.Record.(models.Game))
+	fairnessData := data["fairness"]
+	delete(data, "fairness")
+
+	return gin.H{
+		"game":     data,
+		"fairness": fairnessData,
+	}
+}
+
+func (limboGame) History(_ *gin.Context, userID uint, limit int) ([]gin.H, error) {
+	var games []models.Game
+	if err := config.DB.Preload("ServerSeed").Where("user_id = ? AND game_type = ?", userID, "limbo").Order("id DESC").Limit(limit).Find(&games).Error; err != nil {
+		return nil, err
+	}
+
+	var gameData []gin.H
+	for _, game := range games {
+		gameData = append(gameData, limboGameData(game))
+	}
+	return gameData, nil
+}
+
+// Routes has nothing to add: limbo games are verified through the crash
+// game's verify endpoint.
+func (limboGame) Routes(group *gin.RouterGroup) {}
+
+func limboGameData(game models.Game) gin.H {
+	data := gin.H{
+		"id":         game.ID,
+		"game_type":  game.GameType,
+		"bet_amount": game.BetAmount,
+		"target":     game.Multiplier,
+		"result":     game.CrashPoint,
+		"win_amount": game.WinAmount,
+		"status":     game.Status,
+		"created_at": game.CreatedAt,
+	}
+
+	fairnessData := gin.H{
+		"server_seed_hash": game.ServerSeedHash,
+		"client_seed":      game.ClientSeed,
+		"nonce":            game.Nonce,
+	}
+	if game.ServerSeed != nil {
+		fairnessData["server_seed"] = game.ServerSeed.Seed
+	}
+	data["fairness"] = fairnessData
+
+	return data
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	liveSendBuffer   = 64
+	liveWriteWait    = 10 * time.Second
+	livePongWait     = 60 * time.Second
+	livePingInterval = 25 * time.Second
+	liveMaxMessage   = 512
+)
+
+type LiveEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+	Time time.Time   `json:"time"`
+}
+
+type liveClient struct {
+	userID    uint
+	conn      *websocket.Conn
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type liveHub struct {
+	mu      sync.RWMutex
+	clients map[*liveClient]struct{}
+}
+
+var (
+	hub = &liveHub{clients: make(map[*liveClient]struct{})}
+
+	liveUpgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+)
+
+func (h *liveHub) register(client *liveClient) {
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+}
+
+func (h *liveHub) unregister(client *liveClient) {
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+}
+
+func (h *liveHub) hasClients() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients) > 0
+}
+
+// publish delivers an event to every client, or only to userID's clients when
+// userID is non-zero. Droppable events (multiplier ticks) are skipped for a
+// client whose buffer is full; anything else disconnects the slow client so it
+// can reconnect and resync from the snapshot.
+func (h *liveHub) publish(userID uint, eventType string, data interface{}, droppable bool) {
+	if !h.hasClients() {
+		return
+	}
+
+	message, err := json.Marshal(LiveEvent{Type: eventType, Data: data, Time: time.Now()})
+	if err != nil {
+		log.Printf("Failed to encode live event %s: %v", eventType, err)
+		return
+	}
+
+	h.mu.RLock()
+	var slow []*liveClient
+	for client := range h.clients {
+		if userID != 0 && client.userID != userID {
+			continue
+		}
+		select {
+		case client.send <- message:
+		default:
+			if !droppable {
+				slow = append(slow, client)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range slow {
+		client.close()
+	}
+}
+
+func (h *liveHub) broadcast(eventType string, data interface{}, droppable bool) {
+	h.publish(0, eventType, data, droppable)
+}
+
+func (h *liveHub) sendToUser(userID uint, eventType string, data interface{}) {
+	if userID == 0 {
+		return
+	}
+	h.publish(userID, eventType, data, false)
+}
+
+func (c *liveClient) sendEvent(eventType string, data interface{}) {
+	message, err := json.Marshal(LiveEvent{Type: eventType, Data: data, Time: time.Now()})
+	if err != nil {
+		return
+	}
+	select {
+	case c.send <- message:
+	default:
+		c.close()
+	}
+}
+
+func (c *liveClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		hub.unregister(c)
+	})
+}
+
+func (c *liveClient) readPump() {
+	defer c.close()
+
+	c.conn.SetReadLimit(liveMaxMessage)
+	c.conn.SetReadDeadline(time.Now().Add(livePongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(livePongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (c *liveClient) writePump() {
+	ticker := time.NewTicker(livePingInterval)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+			return
+		}
+	}
+}
+
+func LiveFeed(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	conn, err := liveUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+
+	client := &liveClient{
+		userID: userID,
+		conn:   conn,
+		send:   make(chan []byte, liveSendBuffer),
+		done:   make(chan struct{}),
+	}
+	hub.register(client)
+
+	client.sendEvent("snapshot", liveSnapshot(userID))
+
+	go client.writePump()
+	go client.readPump()
+}
+
+func liveSnapshot(userID uint) gin.H {
+	snapshot := gin.H{}
+
+	if round := scheduler.currentRound(); round != nil {
+		snapshot["round"] = roundData(*round)
+	}
+	snapshot["maintenance"] = maintenanceStatus()
+
+	var pool models.JackpotPool
+	if err := config.DB.Order("id DESC").First(&pool).Error; err == nil && pool.IsActive {
+		snapshot["jackpot"] = gin.H{"balance": pool.Balance}
+	}
+
+	var wallet models.Wallet
+	if err := config.DB.Where("user_id = ?", userID).First(&wallet).Error; err == nil {
+		snapshot["wallet"] = gin.H{
+			"balance":  wallet.Balance,
+			"currency": wallet.Currency,
+		}
+	}
+
+	var games []gin.H
+	activeGamesMux.RLock()
+	for _, game := range activeGames {
+		if game.UserID == userID {
+			games = append(games, gin.H{
+				"id":         game.ID,
+				"round_id":   game.RoundID,
+				"bet_amount": game.BetAmount,
+				"status":     game.Status,
+			})
+		}
+	}
+	activeGamesMux.RUnlock()
+	snapshot["active_games"] = games
+
+	return snapshot
+}
+
+func publishWallet(userID uint, wallet *models.Wallet) {
+	if wallet == nil {
+		return
+	}
+	hub.sendToUser(userID, "wallet", gin.H{
+		"balance":  wallet.Balance,
+		"currency": wallet.Currency,
+	})
+}
@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// maintenanceNotice is how long before a maintenance window live clients get
+// a reminder. The window is also announced when it is created.
+const maintenanceNotice = 10 * time.Minute
+
+var (
+	maintenanceCache    []models.MaintenanceWindow
+	maintenanceLoaded   bool
+	maintenanceCacheMux sync.RWMutex
+)
+
+// maintenanceWindows returns the windows that have not ended or been
+// cancelled, soonest first. They are cached until a window is created,
+// cancelled or ends.
+func maintenanceWindows() []models.MaintenanceWindow {
+	maintenanceCacheMux.RLock()
+	if maintenanceLoaded {
+		windows := maintenanceCache
+		maintenanceCacheMux.RUnlock()
+		return windows
+	}
+	maintenanceCacheMux.RUnlock()
+
+	var windows []models.MaintenanceWindow
+	if err := config.DB.Where("cancelled_at IS NULL AND ends_at > ?", time.Now()).Order("starts_at").Find(&windows).Error; err != nil {
+		return nil
+	}
+
+	maintenanceCacheMux.Lock()
+	maintenanceCache = windows
+	maintenanceLoaded = true
+	maintenanceCacheMux.Unlock()
+
+	return windows
+}
+
+func refreshMaintenanceWindows() {
+	maintenanceCacheMux.Lock()
+	maintenanceCache = nil
+	maintenanceLoaded = false
+	maintenanceCacheMux.Unlock()
+}
+
+func currentMaintenance(now time.Time) *models.MaintenanceWindow {
+	for _, window := range maintenanceWindows() {
+		if !now.Before(window.StartsAt) && now.Before(window.EndsAt) {
+			return &window
+		}
+	}
+	return nil
+}
+
+func maintenanceData(window models.MaintenanceWindow) gin.H {
+	now := time.Now()
+	return gin.H{
+		"id":           window.ID,
+		"starts_at":    window.StartsAt,
+		"ends_at":      window.EndsAt,
+		"message":      window.Message,
+		"active":       window.CancelledAt == nil && !now.Before(window.StartsAt) && now.Before(window.EndsAt),
+		"cancelled_at": window.CancelledAt,
+		"created_at":   window.CreatedAt,
+	}
+}
+
+// maintenanceStatus is what clients see of the maintenance schedule: the
+// running window, if any, and the ones announced after it.
+func maintenanceStatus() gin.H {
+	now := time.Now()
+	status := gin.H{"active": false}
+
+	upcoming := []gin.H{}
+	for _, window := range maintenanceWindows() {
+		switch {
+		case !now.Before(window.EndsAt):
+		case now.Before(window.StartsAt):
+			upcoming = append(upcoming, maintenanceData(window))
+		default:
+			status["active"] = true
+			status["current"] = maintenanceData(window)
+		}
+	}
+	status["upcoming"] = upcoming
+
+	return status
+}
+
+// rejectDuringMaintenance answers a new bet with 503 while a maintenance
+// window is running and reports whether it did. Games that are already in
+// play are not affected.
+func rejectDuringMaintenance(c *gin.Context) bool {
+	window := currentMaintenance(time.Now())
+	if window == nil {
+		return false
+	}
+
+	message := fmt.Sprintf("Betting is paused for maintenance until %s", window.EndsAt.Format(time.RFC3339))
+	if window.Message != "" {
+		message += ": " + window.Message
+	}
+	c.JSON(http.StatusServiceUnavailable, GameResponse{
+		Success: false,
+		Message: message,
+		Code:    codeMaintenance,
+		Data: gin.H{
+			"maintenance": maintenanceData(*window),
+		},
+	})
+	return true
+}
+
+func GetMaintenanceStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Maintenance status retrieved successfully",
+		Data:    maintenanceStatus(),
+	})
+}
+
+// scheduleMaintenanceEvents puts the reminder, start and end of a window on
+// the scheduler. Moments that already passed are skipped, except the end.
+func scheduleMaintenanceEvents(window models.MaintenanceWindow) {
+	now := time.Now()
+	if notice := window.StartsAt.Add(-maintenanceNotice); notice.After(now) {
+		scheduler.push(&scheduledEvent{at: notice, kind: eventMaintenanceNotice, recordID: window.ID})
+	}
+	if window.StartsAt.After(now) {
+		scheduler.push(&scheduledEvent{at: window.StartsAt, kind: eventMaintenanceStart, recordID: window.ID})
+	}
+	scheduler.push(&scheduledEvent{at: window.EndsAt, kind: eventMaintenanceEnd, recordID: window.ID})
+}
+
+func handleMaintenanceEvent(event *scheduledEvent) {
+	var window models.MaintenanceWindow
+	if err := config.DB.First(&window, event.recordID).Error; err != nil || window.CancelledAt != nil {
+		return
+	}
+
+	switch event.kind {
+	case eventMaintenanceNotice:
+		hub.broadcast("maintenance_upcoming", maintenanceData(window), false)
+	case eventMaintenanceStart:
+		log.Printf("Maintenance window %d started, new bets are paused until %s", window.ID, window.EndsAt.Format(time.RFC3339))
+		hub.broadcast("maintenance_started", maintenanceData(window), false)
+	case eventMaintenanceEnd:
+		refreshMaintenanceWindows()
+		log.Printf("Maintenance window %d ended", window.ID)
+		hub.broadcast("maintenance_ended", maintenanceData(window), false)
+	}
+}
+
+// scheduleTimedEvents puts the maintenance windows and settings changes that
+// are still pending back on the scheduler after a restart. Settings changes
+// that came due while the server was down are applied right away.
+func scheduleTimedEvents() {
+	for _, window := range maintenanceWindows() {
+		scheduleMaintenanceEvents(window)
+	}
+
+	var changes []models.ScheduledSettingsChange
+	if err := config.DB.Where("status = ?", "pending").Find(&changes).Error; err != nil {
+		log.Printf("Failed to load scheduled game settings changes: %v", err)
+		return
+	}
+	for _, change := range changes {
+		scheduler.push(&scheduledEvent{at: change.EffectiveAt, kind: eventSettingsChange, recordID: change.ID})
+	}
+}
+
+// scheduleSettingsChange stores a settings update to be applied at its
+// effective time.
+func scheduleSettingsChange(adminID uint, req UpdateGameSettingsRequest) (*models.ScheduledSettingsChange, error) {
+	effectiveAt := *req.EffectiveAt
+	req.EffectiveAt = nil
+
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	change := models.ScheduledSettingsChange{
+		EffectiveAt: effectiveAt,
+		Reason:      req.Reason,
+		Request:     string(encoded),
+		Status:      "pending",
+	}
+	if adminID != 0 {
+		change.AdminID = &adminID
+	}
+	if err := config.DB.Create(&change).Error; err != nil {
+		return nil, err
+	}
+
+	scheduler.push(&scheduledEvent{at: change.EffectiveAt, kind: eventSettingsChange, recordID: change.ID})
+	return &change, nil
+}
+
+// applyScheduledSettingsChange saves a pending change as a "scheduled"
+// version. The request is validated again, against the rules in place now;
+// a change that no longer passes is marked failed.
+func applyScheduledSettingsChange(changeID uint) {
+	tx := config.DB.Begin()
+
+	var change models.ScheduledSettingsChange
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND status = ?", changeID, "pending").First(&change).Error; err != nil {
+		tx.Rollback()
+		return
+	}
+
+	var adminID uint
+	if change.AdminID != nil {
+		adminID = *change.AdminID
+	}
+
+	var req UpdateGameSettingsRequest
+	var version *models.GameSettingsVersion
+	err := json.Unmarshal([]byte(change.Request), &req)
+	if err == nil {
+		var curvePoints string
+		if curvePoints, err = validateGameSettings(req); err == nil {
+			_, version, err = saveGameSettings(tx, req, curvePoints, adminID, "scheduled")
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		log.Printf("Failed to apply scheduled game settings change %d: %v", change.ID, err)
+
+		message := err.Error()
+		if len(message) > 255 {
+			message = message[:255]
+		}
+		config.DB.Model(&models.ScheduledSettingsChange{}).
+			Where("id = ? AND status = ?", change.ID, "pending").
+			Updates(map[string]interface{}{"status": "failed", "error": message})
+		return
+	}
+
+	now := time.Now()
+	if err := tx.Model(&change).Updates(map[string]interface{}{
+		"status":     "applied",
+		"applied_at": now,
+		"version_id": version.ID,
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Failed to apply scheduled game settings change %d: %v", change.ID, err)
+		return
+	}
+
+	tx.Commit()
+	refreshGameSettings()
+	log.Printf("Applied scheduled game settings change %d as version %d", change.ID, version.ID)
+}
+
+func scheduledSettingsChangeData(change models.ScheduledSettingsChange) gin.H {
+	data := gin.H{
+		"id":           change.ID,
+		"effective_at": change.EffectiveAt,
+		"reason":       change.Reason,
+		"request":      json.RawMessage(change.Request),
+		"status":       change.Status,
+		"applied_at":   change.AppliedAt,
+		"version_id":   change.VersionID,
+		"error":        change.Error,
+		"admin_id":     change.AdminID,
+		"created_at":   change.CreatedAt,
+	}
+	if change.Admin != nil {
+		data["admin"] = gin.H{
+			"id":       change.Admin.ID,
+			"username": change.Admin.Username,
+		}
+	}
+	return data
+}
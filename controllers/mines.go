@@ -0,0 +1,475 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StartMinesRequest struct {
+	BetAmount float64 `json:"bet_amount" binding:"required,gt=0"`
+	Mines     int     `json:"mines" binding:"required,gte=1,lte=24"`
+}
+
+type RevealMinesRequest struct {
+	GameID uint `json:"game_id" binding:"required"`
+	Tile   *int `json:"tile" binding:"required,gte=0,lte=24"`
+}
+
+type CashoutMinesRequest struct {
+	GameID uint `json:"game_id" binding:"required"`
+}
+
+func loadMinesSettings() (models.MinesSettings, error) {
+	var settings models.MinesSettings
+	err := config.DB.Order("id DESC").First(&settings).Error
+	return settings, err
+}
+
+func decodeTiles(raw string) []int {
+	var tiles []int
+	if raw != "" {
+		json.Unmarshal([]byte(raw), &tiles)
+	}
+	return tiles
+}
+
+func encodeTiles(tiles []int) string {
+	encoded, _ := json.Marshal(tiles)
+	return string(encoded)
+}
+
+func containsTile(tiles []int, tile int) bool {
+	for _, t := range tiles {
+		if t == tile {
+			return true
+		}
+	}
+	return false
+}
+
+// lockActiveMinesGame loads one of the user's active boards with a row lock, so
+// a reveal and a cashout of the same board are applied one after another and a
+// finished board can't be played again.
+func lockActiveMinesGame(tx *gorm.DB, gameID, userID uint) (*models.MinesGame, error) {
+	var game models.MinesGame
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ? AND status = ?", gameID, userID, "active").
+		First(&game).Error
+	if err != nil {
+		return nil, err
+	}
+	return &game, nil
+}
+
+// minesGame opens a board with its mines placed from the bet's seed. The
+// board stays open while tiles are revealed and is settled by
+// settleMinesGame on a mine, a cash out or a cleared board.
+type minesGame struct{}
+
+type minesBet struct {
+	mines     int
+	houseEdge float64
+}
+
+func (minesGame) Info() GameInfo {
+	return GameInfo{
+		Type:        "mines",
+		Name:        "Mines",
+		PlayPath:    "/start",
+		HistoryPath: "/games",
+		HistoryKey:  "games",
+	}
+}
+
+func (minesGame) ValidateBet(c *gin.Context, _ models.GameSettings) (*GameBet, error) {
+	var req StartMinesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, newGameError(http.StatusBadRequest, "Invalid request data: %s", err.Error())
+	}
+
+	settings, err := loadMinesSettings()
+	if err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Mines settings not found")
+	}
+
+	if !settings.IsActive {
+		return nil, newGameError(http.StatusServiceUnavailable, "Mines is currently unavailable")
+	}
+
+	if req.Mines < settings.MinMines || req.Mines > settings.MaxMines {
+		return nil, newGameError(http.StatusBadRequest, "Mines must be between %d and %d", settings.MinMines, settings.MaxMines)
+	}
+
+	return &GameBet{
+		Amount:      req.BetAmount,
+		MinBet:      settings.MinBetAmount,
+		MaxBet:      settings.MaxBetAmount,
+		Description: fmt.Sprintf("Bet placed for mines game with %d mines", req.Mines),
+		Params: minesBet{
+			mines:     req.Mines,
+			houseEdge: settings.HouseEdge,
+		},
+	}, nil
+}
+
+func (minesGame) Resolve(tx *gorm.DB, bet *GameBet, seed GameSeed) (*GameOutcome, error) {
+	params := bet.Params.(minesBet)
+
+	// The wallet lock serialises the user's requests, so this check can't be
+	// raced by a second start.
+	var activeBoards int64
+	tx.Model(&models.MinesGame{}).Where("user_id = ? AND status = ?", bet.UserID, "active").Count(&activeBoards)
+	if activeBoards > 0 {
+		return nil, newGameError(http.StatusConflict, "You already have an active mines game")
+	}
+
+	game := models.MinesGame{
+		UserID:         bet.UserID,
+		BetAmount:      bet.Amount,
+		MineCount:      params.mines,
+		HouseEdge:      params.houseEdge,
+		MinePositions:  encodeTiles(fairness.MinePositions(seed.ServerSeed.Seed, seed.ClientSeed, seed.Nonce, params.mines)),
+		RevealedTiles:  encodeTiles([]int{}),
+		Multiplier:     1.0,
+		Status:         "active",
+		ServerSeedID:   &seed.ServerSeed.ID,
+		ServerSeedHash: seed.ServerSeed.Hash,
+		ClientSeed:     seed.ClientSeed,
+		Nonce:          seed.Nonce,
+	}
+
+	if err := tx.Create(&game).Error; err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to create game")
+	}
+
+	return &GameOutcome{
+		RecordID: game.ID,
+		Record:   game,
+		Message:  "Mines game started",
+		Pending:  true,
+	}, nil
+}
+
+func (minesGame) Settle(outcome *GameOutcome) []models.Transaction {
+	return nil
+}
+
+func (minesGame) Describe(outcome *GameOutcome) gin.H {
+	return gin.H{
+		"game": minesGameData(outcome.Record.(models.MinesGame)),
+	}
+}
+
+func (minesGame) History(_ *gin.Context, userID uint, limit int) ([]gin.H, error) {
+	var games []models.MinesGame
+	if err := config.DB.Preload("ServerSeed").Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&games).Error; err != nil {
+		return nil, err
+	}
+
+	var gameData []gin.H
+	for _, game := range games {
+		gameData = append(gameData, minesGameData(game))
+	}
+	return gameData, nil
+}
+
+func (minesGame) Routes(group *gin.RouterGroup) {
+	group.POST("/reveal", RevealMinesTile)
+	group.POST("/cashout", CashoutMines)
+	group.GET("/active", GetActiveMinesGame)
+	group.GET("/settings", GetMinesSettings)
+}
+
+func RevealMinesTile(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req RevealMinesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	game, err := lockActiveMinesGame(tx, req.GameID, userID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Active mines game not found",
+		})
+		return
+	}
+
+	tile := *req.Tile
+	revealed := decodeTiles(game.RevealedTiles)
+	if containsTile(revealed, tile) {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Tile is already revealed",
+		})
+		return
+	}
+
+	revealed = append(revealed, tile)
+	game.RevealedTiles = encodeTiles(revealed)
+
+	var wallet *models.Wallet
+	message := "Safe tile"
+	if containsTile(decodeTiles(game.MinePositions), tile) {
+		message = "Mine hit - game lost"
+		wallet, err = settleMinesGame(tx, game, false)
+	} else {
+		game.Multiplier = fairness.MinesMultiplier(game.MineCount, len(revealed), game.HouseEdge)
+		switch {
+		case len(revealed) == fairness.MinesTiles-game.MineCount:
+			message = "All safe tiles revealed - game won!"
+			wallet, err = settleMinesGame(tx, game, true)
+		case minesMaxWinReached(game):
+			message = "Max win per bet reached - game cashed out"
+			wallet, err = settleMinesGame(tx, game, true)
+		default:
+			err = tx.Save(game).Error
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to update game",
+		})
+		return
+	}
+
+	tx.Commit()
+
+	respondMinesGame(c, game, wallet, message)
+}
+
+func CashoutMines(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req CashoutMinesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	tx := config.DB.Begin()
+
+	game, err := lockActiveMinesGame(tx, req.GameID, userID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Active mines game not found",
+		})
+		return
+	}
+
+	if len(decodeTiles(game.RevealedTiles)) == 0 {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Reveal at least one tile before cashing out",
+		})
+		return
+	}
+
+	wallet, err := settleMinesGame(tx, game, true)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to settle game",
+		})
+		return
+	}
+
+	tx.Commit()
+
+	respondMinesGame(c, game, wallet, "Game won!")
+}
+
+// minesMaxWinReached reports whether cashing the board out now would already
+// pay the max win per bet, so that revealing more tiles only adds risk.
+func minesMaxWinReached(game *models.MinesGame) bool {
+	settings, err := loadGameSettings()
+	if err != nil || settings.MaxWinPerBet <= 0 {
+		return false
+	}
+	return game.BetAmount*game.Multiplier >= settings.MaxWinPerBet
+}
+
+// settleMinesGame finishes a locked board and books the win or loss on the
+// user's wallet within tx.
+func settleMinesGame(tx *gorm.DB, game *models.MinesGame, won bool) (*models.Wallet, error) {
+	outcome := &GameOutcome{
+		RecordID:        game.ID,
+		LossDescription: fmt.Sprintf("Mines lost - hit a mine after %d safe tiles", len(decodeTiles(game.RevealedTiles))-1),
+	}
+
+	settings, err := loadGameSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.Transaction
+	game.Status = "lost"
+	game.WinAmount = 0
+	if won {
+		game.Status = "won"
+		game.WinAmount = game.BetAmount * game.Multiplier
+		if settings.MaxWinPerBet > 0 && game.WinAmount > settings.MaxWinPerBet {
+			game.WinAmount = settings.MaxWinPerBet
+			game.Capped = true
+		}
+		entries = append(entries, models.Transaction{
+			Type:        "win",
+			Amount:      game.WinAmount,
+			Description: fmt.Sprintf("Mines won with multiplier %.4fx", game.Multiplier),
+		})
+	}
+
+	if err := tx.Save(game).Error; err != nil {
+		return nil, err
+	}
+
+	wallet, err := lockWallet(tx, game.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := settleGame(tx, wallet, "mines", outcome, entries); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+func respondMinesGame(c *gin.Context, game *models.MinesGame, wallet *models.Wallet, message string) {
+	if game.Status != "active" && game.ServerSeedID != nil {
+		var serverSeed models.ServerSeed
+		if err := config.DB.First(&serverSeed, *game.ServerSeedID).Error; err == nil {
+			game.ServerSeed = &serverSeed
+		}
+	}
+
+	data := gin.H{
+		"game": minesGameData(*game),
+	}
+	if wallet != nil {
+		data["wallet"] = gin.H{
+			"balance":  wallet.Balance,
+			"currency": wallet.Currency,
+		}
+		publishWallet(game.UserID, wallet)
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// minesGameData hides the mine positions and the server seed while the board
+// is still being played.
+func minesGameData(game models.MinesGame) gin.H {
+	revealed := decodeTiles(game.RevealedTiles)
+	safeLeft := fairness.MinesTiles - game.MineCount - len(revealed)
+
+	data := gin.H{
+		"id":              game.ID,
+		"bet_amount":      game.BetAmount,
+		"mines":           game.MineCount,
+		"revealed_tiles":  revealed,
+		"multiplier":      game.Multiplier,
+		"next_multiplier": fairness.MinesMultiplier(game.MineCount, len(revealed)+1, game.HouseEdge),
+		"win_amount":      game.WinAmount,
+		"capped":          game.Capped,
+		"status":          game.Status,
+		"created_at":      game.CreatedAt,
+	}
+
+	fairnessData := gin.H{
+		"server_seed_hash": game.ServerSeedHash,
+		"client_seed":      game.ClientSeed,
+		"nonce":            game.Nonce,
+	}
+
+	if game.Status == "active" {
+		data["safe_tiles_left"] = safeLeft
+	} else {
+		delete(data, "next_multiplier")
+		data["mine_positions"] = decodeTiles(game.MinePositions)
+		if game.ServerSeed != nil {
+			fairnessData["server_seed"] = game.ServerSeed.Seed
+			fairnessData["verified"] = encodeTiles(fairness.MinePositions(game.ServerSeed.Seed, game.ClientSeed, game.Nonce, game.MineCount)) == game.MinePositions
+		}
+	}
+	data["fairness"] = fairnessData
+
+	return data
+}
+
+func GetActiveMinesGame(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var game models.MinesGame
+	if err := config.DB.Where("user_id = ? AND status = ?", userID, "active").First(&game).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "No active mines game",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Active mines game retrieved successfully",
+		Data: gin.H{
+			"game": minesGameData(game),
+		},
+	})
+}
+
+func GetMinesSettings(c *gin.Context) {
+	settings, err := loadMinesSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Mines settings not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Mines settings retrieved successfully",
+		Data: gin.H{
+			"settings": gin.H{
+				"min_bet_amount": settings.MinBetAmount,
+				"max_bet_amount": settings.MaxBetAmount,
+				"house_edge":     settings.HouseEdge,
+				"min_mines":      settings.MinMines,
+				"max_mines":      settings.MaxMines,
+				"is_active":      settings.IsActive,
+			},
+		},
+	})
+}
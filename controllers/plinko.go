@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DropPlinkoRequest struct {
+	BetAmount float64 `json:"bet_amount" binding:"required,gt=0"`
+	Rows      int     `json:"rows" binding:"required,gte=8,lte=16"`
+	Risk      string  `json:"risk" binding:"required,oneof=low medium high"`
+}
+
+func decodeMultipliers(raw string) []float64 {
+	var multipliers []float64
+	if raw != "" {
+		json.Unmarshal([]byte(raw), &multipliers)
+	}
+	return multipliers
+}
+
+func maxOf(values []float64) float64 {
+	var max float64
+	for _, value := range values {
+		if value > max {
+			max = value
+		}
+	}
+	return max
+}
+
+// plinkoGame drops a single ball and settles it in the same request. Bet
+// limits and the max win cap come from the crash game settings.
+type plinkoGame struct{}
+
+type plinkoBet struct {
+	rows        int
+	risk        string
+	multipliers []float64
+}
+
+func (plinkoGame) Info() GameInfo {
+	return GameInfo{
+		Type:        "plinko",
+		Name:        "Plinko",
+		PlayPath:    "/drop",
+		HistoryPath: "/drops",
+		HistoryKey:  "drops",
+		Event:       "plinko_drop",
+	}
+}
+
+func (plinkoGame) ValidateBet(c *gin.Context, settings models.GameSettings) (*GameBet, error) {
+	var req DropPlinkoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, newGameError(http.StatusBadRequest, "Invalid request data: %s", err.Error())
+	}
+
+	var table models.PlinkoPayoutTable
+	if err := config.DB.Where("`rows` = ? AND risk = ?", req.Rows, req.Risk).First(&table).Error; err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Plinko payout table not found")
+	}
+
+	multipliers := decodeMultipliers(table.Multipliers)
+	if len(multipliers) != req.Rows+1 {
+		return nil, newGameError(http.StatusInternalServerError, "Plinko payout table is invalid")
+	}
+
+	return &GameBet{
+		Amount:      req.BetAmount,
+		MinBet:      settings.MinBetAmount,
+		MaxBet:      settings.MaxBetAmount,
+		MaxPayout:   req.BetAmount * maxOf(multipliers),
+		Description: fmt.Sprintf("Bet placed for plinko (%d rows, %s risk)", req.Rows, req.Risk),
+		Params:      plinkoBet{rows: req.Rows, risk: req.Risk, multipliers: multipliers},
+	}, nil
+}
+
+func (plinkoGame) Resolve(tx *gorm.DB, bet *GameBet, seed GameSeed) (*GameOutcome, error) {
+	params := bet.Params.(plinkoBet)
+
+	path, slot := fairness.PlinkoPath(seed.ServerSeed.Seed, seed.ClientSeed, seed.Nonce, params.rows)
+	encodedPath, _ := json.Marshal(path)
+
+	drop := models.PlinkoDrop{
+		UserID:         bet.UserID,
+		BetAmount:      bet.Amount,
+		Rows:           params.rows,
+		Risk:           params.risk,
+		Path:           string(encodedPath),
+		Slot:           slot,
+		Multiplier:     params.multipliers[slot],
+		WinAmount:      bet.Amount * params.multipliers[slot],
+		ServerSeedID:   &seed.ServerSeed.ID,
+		ServerSeedHash: seed.ServerSeed.Hash,
+		ClientSeed:     seed.ClientSeed,
+		Nonce:          seed.Nonce,
+	}
+
+	if err := tx.Create(&drop).Error; err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to record plinko drop")
+	}
+	drop.ServerSeed = seed.ServerSeed
+
+	return &GameOutcome{
+		RecordID:        drop.ID,
+		Record:          drop,
+		Message:         fmt.Sprintf("Ball landed on %.2fx", drop.Multiplier),
+		LossDescription: fmt.Sprintf("Plinko lost - landed in slot %d", slot),
+	}, nil
+}
+
+func (plinkoGame) Settle(outcome *GameOutcome) []models.Transaction {
+	drop := outcome.Record.(models.PlinkoDrop)
+	if drop.WinAmount <= 0 {
+		return nil
+	}
+	return []models.Transaction{{
+		Type:        "win",
+		Amount:      drop.WinAmount,
+		Description: fmt.Sprintf("Plinko paid %.2fx - landed in slot %d", drop.Multiplier, drop.Slot),
+	}}
+}
+
+func (plinkoGame) Describe(outcome *GameOutcome) gin.H {
+	return gin.H{
+		"drop": plinkoDropData(outcome.Record.(models.PlinkoDrop)),
+	}
+}
+
+func (plinkoGame) History(_ *gin.Context, userID uint, limit int) ([]gin.H, error) {
+	var drops []models.PlinkoDrop
+	if err := config.DB.Preload("ServerSeed").Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&drops).Error; err != nil {
+		return nil, err
+	}
+
+	var dropData []gin.H
+	for _, drop := range drops {
+		dropData = append(dropData, plinkoDropData(drop))
+	}
+	return dropData, nil
+}
+
+func (plinkoGame) Routes(group *gin.RouterGroup) {
+	group.GET("/tables", GetPlinkoTables)
+	group.GET("/drop/:id/verify", VerifyPlinkoDrop)
+}
+
+func plinkoDropData(drop models.PlinkoDrop) gin.H {
+	var path []int
+	json.Unmarshal([]byte(drop.Path), &path)
+
+	data := gin.H{
+		"id":         drop.ID,
+		"bet_amount": drop.BetAmount,
+		"rows":       drop.Rows,
+		"risk":       drop.Risk,
+		"path":       path,
+		"slot":       drop.Slot,
+		"multiplier": drop.Multiplier,
+		"win_amount": drop.WinAmount,
+		"created_at": drop.CreatedAt,
+	}
+
+	fairnessData := gin.H{
+		"server_seed_hash": drop.ServerSeedHash,
+		"client_seed":      drop.ClientSeed,
+		"nonce":            drop.Nonce,
+	}
+	if drop.ServerSeed != nil {
+		fairnessData["server_seed"] = drop.ServerSeed.Seed
+	}
+	data["fairness"] = fairnessData
+
+	return data
+}
+
+func plinkoTableData(table models.PlinkoPayoutTable) gin.H {
+	multipliers := decodeMultipliers(table.Multipliers)
+	return gin.H{
+		"rows":            table.Rows,
+		"risk":            table.Risk,
+		"multipliers":     multipliers,
+		"theoretical_rtp": fairness.PlinkoRTP(multipliers) * 100,
+	}
+}
+
+func GetPlinkoTables(c *gin.Context) {
+	var tables []models.PlinkoPayoutTable
+	if err := config.DB.Order("`rows` ASC, id ASC").Find(&tables).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to retrieve plinko payout tables",
+		})
+		return
+	}
+
+	var tableData []gin.H
+	for _, table := range tables {
+		tableData = append(tableData, plinkoTableData(table))
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Plinko payout tables retrieved successfully",
+		Data: gin.H{
+			"tables": tableData,
+		},
+	})
+}
+
+func VerifyPlinkoDrop(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	dropID := c.Param("id")
+
+	var drop models.PlinkoDrop
+	if err := config.DB.Preload("ServerSeed").First(&drop, dropID).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Plinko drop not found",
+		})
+		return
+	}
+
+	if drop.UserID != userID {
+		c.JSON(http.StatusForbidden, GameResponse{
+			Success: false,
+			Message: "Access denied",
+		})
+		return
+	}
+
+	if drop.ServerSeed == nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Plinko drop was not played with a provably fair seed",
+		})
+		return
+	}
+
+	serverSeed := drop.ServerSeed
+	path, slot := fairness.PlinkoPath(serverSeed.Seed, drop.ClientSeed, drop.Nonce, drop.Rows)
+	encodedPath, _ := json.Marshal(path)
+	seedMatches := fairness.HashSeed(serverSeed.Seed) == drop.ServerSeedHash
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Plinko drop verification retrieved successfully",
+		Data: gin.H{
+			"drop": plinkoDropData(drop),
+			"fairness": gin.H{
+				"server_seed":      serverSeed.Seed,
+				"server_seed_hash": drop.ServerSeedHash,
+				"client_seed":      drop.ClientSeed,
+				"nonce":            drop.Nonce,
+				"computed_path":    path,
+				"computed_slot":    slot,
+				"verified":         seedMatches && string(encodedPath) == drop.Path && slot == drop.Slot,
+			},
+		},
+	})
+}
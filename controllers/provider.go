@@ -0,0 +1,410 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GameProvider is a game that plugs into the shared wallet and settlement
+// flow. PlayGame and GameHistory take care of the player checks, bet limits,
+// seeds, wallet entries and history for every provider, so a provider only
+// implements the rules of its own game. Instant games are settled in the
+// request that places the bet; games that stay open (a crash bet, a mines
+// board, a blackjack hand, a keno ticket) return a pending outcome and book
+// their result through settleGame when they end.
+type GameProvider interface {
+	// Info describes the game and where its endpoints are mounted.
+	Info() GameInfo
+	// ValidateBet binds the play request and returns the stake and the
+	// limits it has to respect. Nothing has been locked at this point.
+	ValidateBet(c *gin.Context, settings models.GameSettings) (*GameBet, error)
+	// Resolve draws the outcome from seed, or opens the game, and records it
+	// inside tx. The player's wallet is locked.
+	Resolve(tx *gorm.DB, bet *GameBet, seed GameSeed) (*GameOutcome, error)
+	// Settle returns the wallet entries that pay the outcome out. An empty
+	// result is recorded as a loss, unless the outcome is pending.
+	Settle(outcome *GameOutcome) []models.Transaction
+	// Describe returns the response data for a resolved outcome.
+	Describe(outcome *GameOutcome) gin.H
+	// History returns the player's most recent records, newest first. c
+	// carries any filters the game supports.
+	History(c *gin.Context, userID uint, limit int) ([]gin.H, error)
+	// Routes mounts any endpoints beyond play and history.
+	Routes(group *gin.RouterGroup)
+}
+
+// gameCommitter is implemented by providers with work to do once a bet is
+// committed, such as handing an open game to a scheduler or announcing it to
+// the room.
+type gameCommitter interface {
+	Committed(user models.User, outcome *GameOutcome)
+}
+
+// GameInfo describes a game. Its endpoints are mounted under /api/<type>
+// unless Path says otherwise. SharedSeed marks games whose outcome comes
+// from a seed that is not the bet's own, such as a round, a draw or a shoe,
+// so PlayGame doesn't use up a seed of the player's pair.
+type GameInfo struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	PlayPath    string `json:"play_path"`
+	HistoryPath string `json:"history_path"`
+	HistoryKey  string `json:"-"`
+	Event       string `json:"-"`
+	SharedSeed  bool   `json:"-"`
+}
+
+func (i GameInfo) BasePath() string {
+	if i.Path != "" {
+		return i.Path
+	}
+	return "/api/" + i.Type
+}
+
+// GameBet is a validated bet. MaxPayout is checked against the max win per
+// bet before the wallet is touched; providers that cap wins at settlement
+// instead leave it at 0. Lock, when set, is held from before the bet's
+// transaction until the bet is committed, for checks that depend on other
+// bets still being placed.
+type GameBet struct {
+	UserID      uint
+	Amount      float64
+	MinBet      float64
+	MaxBet      float64
+	MaxPayout   float64
+	Description string
+	Settings    models.GameSettings
+	Params      interface{}
+	Lock        sync.Locker
+}
+
+type GameSeed struct {
+	ServerSeed *models.ServerSeed
+	ClientSeed string
+	Nonce      uint64
+}
+
+// GameOutcome is a recorded result. GameID is set by providers that record
+// into models.Game so their wallet entries stay linked to it. A pending
+// outcome is a game that is still being played.
+type GameOutcome struct {
+	RecordID        uint
+	GameID          *uint
+	Record          interface{}
+	Message         string
+	LossDescription string
+	Pending         bool
+}
+
+// gameError carries the status, message and optional code a provider wants the
+// player to see. Any other error is reported as an internal failure.
+type gameError struct {
+	status  int
+	message string
+	code    string
+}
+
+func (e *gameError) Error() string {
+	return e.message
+}
+
+func newGameError(status int, format string, args ...interface{}) error {
+	return &gameError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// gameProviders lists the registered games in the order they are listed and
+// routed. A new game only needs to be added here.
+var gameProviders = []GameProvider{
+	crashGame{},
+	minesGame{},
+	blackjackGame{},
+	kenoGame{},
+	diceGame{},
+	limboGame{},
+	plinkoGame{},
+	rouletteGame{},
+	slotsGame{},
+}
+
+func GameProviders() []GameProvider {
+	return gameProviders
+}
+
+func gameReference(gameType string, recordID uint) string {
+	return fmt.Sprintf("%s:%d", gameType, recordID)
+}
+
+// debitStake takes a stake for outcome from wallet within tx: the bet that
+// opens it or, for a game that stays open, any stake added while it is played.
+func debitStake(tx *gorm.DB, wallet *models.Wallet, gameType string, outcome *GameOutcome, amount float64, description string) error {
+	_, err := debitWallet(tx, wallet, amount, models.Transaction{
+		GameID:      outcome.GameID,
+		GameType:    gameType,
+		Type:        "bet",
+		Reference:   gameReference(gameType, outcome.RecordID),
+		Description: description,
+	})
+	return err
+}
+
+// settleGame books the entries that pay outcome out on wallet within tx,
+// linked to the game they belong to. Without entries a finished outcome is
+// booked as a loss. Every game settles through here, whether in the request
+// that placed the bet or when an open game ends.
+func settleGame(tx *gorm.DB, wallet *models.Wallet, gameType string, outcome *GameOutcome, entries []models.Transaction) error {
+	if len(entries) == 0 && !outcome.Pending {
+		entries = append(entries, models.Transaction{
+			Type:        "loss",
+			Description: outcome.LossDescription,
+		})
+	}
+
+	for _, entry := range entries {
+		entry.GameID = outcome.GameID
+		entry.GameType = gameType
+		entry.Reference = gameReference(gameType, outcome.RecordID)
+		if _, err := creditWallet(tx, wallet, entry.Amount, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func gameErrorResponse(c *gin.Context, err error, fallback string) {
+	var gerr *gameError
+	if errors.As(err, &gerr) {
+		c.JSON(gerr.status, GameResponse{
+			Success: false,
+			Message: gerr.message,
+			Code:    gerr.code,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, GameResponse{
+		Success: false,
+		Message: fallback,
+	})
+}
+
+// PlayGame places one bet on provider, and settles it right away unless the
+// game stays open.
+func PlayGame(provider GameProvider) gin.HandlerFunc {
+	info := provider.Info()
+
+	return func(c *gin.Context) {
+		userID := c.GetUint("user_id")
+
+		var user models.User
+		if err := config.DB.First(&user, userID).Error; err != nil {
+			c.JSON(http.StatusNotFound, GameResponse{
+				Success: false,
+				Message: "User not found",
+			})
+			return
+		}
+
+		if user.Status == "banned" {
+			c.JSON(http.StatusForbidden, GameResponse{
+				Success: false,
+				Message: "Account is banned",
+			})
+			return
+		}
+
+		if rejectDuringMaintenance(c) {
+			return
+		}
+
+		settings, err := loadGameSettings()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, GameResponse{
+				Success: false,
+				Message: "Game settings not found",
+			})
+			return
+		}
+
+		bet, err := provider.ValidateBet(c, settings)
+		if err != nil {
+			gameErrorResponse(c, err, "Failed to validate bet")
+			return
+		}
+		bet.UserID = userID
+		bet.Settings = settings
+
+		if bet.Amount < bet.MinBet || bet.Amount > bet.MaxBet {
+			c.JSON(http.StatusBadRequest, GameResponse{
+				Success: false,
+				Message: fmt.Sprintf("Bet amount must be between %.2f and %.2f", bet.MinBet, bet.MaxBet),
+			})
+			return
+		}
+
+		if settings.MaxWinPerBet > 0 && bet.MaxPayout > settings.MaxWinPerBet {
+			c.JSON(http.StatusBadRequest, GameResponse{
+				Success: false,
+				Message: fmt.Sprintf("Potential win exceeds the max win per bet of %.2f", settings.MaxWinPerBet),
+			})
+			return
+		}
+
+		if err := ensureSeedPool(); err != nil {
+			c.JSON(http.StatusInternalServerError, GameResponse{
+				Success: false,
+				Message: "Failed to prepare server seeds",
+			})
+			return
+		}
+
+		if bet.Lock != nil {
+			bet.Lock.Lock()
+			defer bet.Lock.Unlock()
+		}
+
+		tx := config.DB.Begin()
+
+		wallet, err := lockWallet(tx, userID)
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, GameResponse{
+				Success: false,
+				Message: "Wallet not found",
+			})
+			return
+		}
+
+		var seed GameSeed
+		if !info.SharedSeed {
+			serverSeed, clientSeed, nonce, err := nextGameSeed(tx, userID)
+			if err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, GameResponse{
+					Success: false,
+					Message: "Failed to assign server seed",
+				})
+				return
+			}
+			seed = GameSeed{ServerSeed: serverSeed, ClientSeed: clientSeed, Nonce: nonce}
+		}
+
+		outcome, err := provider.Resolve(tx, bet, seed)
+		if err != nil {
+			tx.Rollback()
+			gameErrorResponse(c, err, fmt.Sprintf("Failed to record %s game", info.Type))
+			return
+		}
+
+		oldBalance := wallet.Balance
+		if err := debitStake(tx, wallet, info.Type, outcome, bet.Amount, bet.Description); err != nil {
+			tx.Rollback()
+			if errors.Is(err, errInsufficientBalance) {
+				c.JSON(http.StatusBadRequest, GameResponse{
+					Success: false,
+					Message: "Insufficient wallet balance",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, GameResponse{
+				Success: false,
+				Message: "Failed to deduct bet amount",
+			})
+			return
+		}
+
+		if err := settleGame(tx, wallet, info.Type, outcome, provider.Settle(outcome)); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, GameResponse{
+				Success: false,
+				Message: "Failed to update wallet",
+			})
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			c.JSON(http.StatusInternalServerError, GameResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to record %s game", info.Type),
+			})
+			return
+		}
+
+		if committer, ok := provider.(gameCommitter); ok {
+			committer.Committed(user, outcome)
+		}
+
+		data := provider.Describe(outcome)
+		data["wallet"] = gin.H{
+			"old_balance": oldBalance,
+			"new_balance": wallet.Balance,
+			"currency":    wallet.Currency,
+		}
+
+		publishWallet(userID, wallet)
+		if info.Event != "" {
+			hub.sendToUser(userID, info.Event, data)
+		}
+
+		c.JSON(http.StatusCreated, GameResponse{
+			Success: true,
+			Message: outcome.Message,
+			Data:    data,
+		})
+	}
+}
+
+// GameHistory lists the player's most recent records on provider.
+func GameHistory(provider GameProvider) gin.HandlerFunc {
+	info := provider.Info()
+
+	return func(c *gin.Context) {
+		userID := c.GetUint("user_id")
+
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		records, err := provider.History(c, userID, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, GameResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to retrieve %s %s", info.Type, info.HistoryKey),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, GameResponse{
+			Success: true,
+			Message: fmt.Sprintf("%s %s retrieved successfully", info.Name, info.HistoryKey),
+			Data: gin.H{
+				info.HistoryKey: records,
+			},
+		})
+	}
+}
+
+func GetGameProviders(c *gin.Context) {
+	var games []GameInfo
+	for _, provider := range gameProviders {
+		info := provider.Info()
+		info.Path = info.BasePath()
+		games = append(games, info)
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Games retrieved successfully",
+		Data: gin.H{
+			"games": games,
+		},
+	})
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/models"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	recoveryResume = "resume"
+	recoveryCrash  = "crash"
+	recoveryRefund = "refund"
+)
+
+func recoveryPolicy() string {
+	switch policy := os.Getenv("RECOVERY_POLICY"); policy {
+	case recoveryCrash, recoveryRefund:
+		return policy
+	default:
+		return recoveryResume
+	}
+}
+
+// roundInProgress reports whether a round can be picked up again, i.e. it did
+// not reach its crash point while the server was down.
+func roundInProgress(round *models.Round) bool {
+	if round == nil {
+		return false
+	}
+	switch round.Status {
+	case "betting":
+		return true
+	case "running":
+		return round.StartedAt != nil && roundMultiplier(round) < round.CrashPoint
+	}
+	return false
+}
+
+// recoverActiveGames deals with the bets that were still open when the process
+// stopped. Depending on RECOVERY_POLICY they go back into the scheduler, are
+// settled as if their round had crashed, or are refunded. Bets whose round
+// already passed its crash point can't be resumed and are settled instead.
+// It runs before the scheduler resumes the open round.
+func recoverActiveGames() {
+	var games []models.Game
+	if err := config.DB.Preload("Round").Where("status = ?", "active").Find(&games).Error; err != nil {
+		log.Printf("Failed to load active games for recovery: %v", err)
+		return
+	}
+	if len(games) == 0 {
+		return
+	}
+
+	policy := recoveryPolicy()
+	closedRounds := make(map[uint]*models.Round)
+	actions := make(map[string]int)
+
+	for i := range games {
+		game := &games[i]
+		round := game.Round
+		game.Round = nil
+
+		action := policy
+		if round == nil {
+			action = recoveryRefund
+		} else if action == recoveryResume && !roundInProgress(round) {
+			action = recoveryCrash
+		}
+
+		var recovery *models.GameRecovery
+		var err error
+		switch action {
+		case recoveryResume:
+			if recovery, err = resumeRecoveredGame(game, policy); err == nil {
+				activeGamesMux.Lock()
+				activeGames[game.ID] = game
+				activeGamesMux.Unlock()
+			}
+		case recoveryCrash:
+			recovery, err = settleRecoveredGame(game, policy)
+		case recoveryRefund:
+			recovery, err = refundRecoveredGame(game, policy)
+		}
+		if err != nil {
+			log.Printf("Failed to recover game %d: %v", game.ID, err)
+			continue
+		}
+		actions[recovery.Action]++
+
+		if action != recoveryResume && round != nil && round.Status != "crashed" {
+			closedRounds[round.ID] = round
+		}
+	}
+
+	for _, round := range closedRounds {
+		closeRecoveredRound(round)
+	}
+
+	log.Printf("Recovered %d active games with policy %s: %d resumed, %d won, %d lost, %d refunded",
+		len(games), policy, actions["resumed"], actions["won"], actions["lost"], actions["refunded"])
+}
+
+func settleRecoveredGame(game *models.Game, policy string) (*models.GameRecovery, error) {
+	_, response := completeGame(game, "recovery")
+	if response == nil || !response.Success {
+		message := "settlement failed"
+		if response != nil {
+			message = response.Message
+		}
+		return nil, errors.New(message)
+	}
+
+	note := fmt.Sprintf("Settled at multiplier %.2fx, crash point %.2fx", game.Multiplier, game.CrashPoint)
+	return saveRecovery(game, policy, game.Status, game.WinAmount, note)
+}
+
+func refundRecoveredGame(game *models.Game, policy string) (*models.GameRecovery, error) {
+	tx := config.DB.Begin()
+
+	result := tx.Model(&models.Game{}).
+		Where("id = ? AND status = ?", game.ID, "active").
+		Updates(map[string]interface{}{"status": "refunded", "is_completed": true})
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, errors.New("game already settled")
+	}
+
+	wallet, err := lockWallet(tx, game.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Stake that was already cashed out partially stays paid.
+	refund := game.BetAmount - game.CashedOutStake
+	if _, err := creditWallet(tx, wallet, refund, models.Transaction{
+		GameID:      &game.ID,
+		GameType:    game.GameType,
+		Type:        "refund",
+		Description: "Bet refunded - game recovered after restart",
+	}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	recovery := models.GameRecovery{
+		GameID:    game.ID,
+		RoundID:   game.RoundID,
+		UserID:    game.UserID,
+		Policy:    policy,
+		Action:    "refunded",
+		BetAmount: game.BetAmount,
+		Amount:    refund,
+		Note:      "Stake returned to the wallet",
+	}
+	if err := tx.Create(&recovery).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+
+	game.Status = "refunded"
+	game.IsCompleted = true
+	return &recovery, nil
+}
+
+// resumeRecoveredGame logs a resumed bet in the player's transactions with a
+// zero amount, next to its recovery record. Nothing is paid until the round
+// settles the bet.
+func resumeRecoveredGame(game *models.Game, policy string) (*models.GameRecovery, error) {
+	tx := config.DB.Begin()
+
+	wallet, err := lockWallet(tx, game.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if _, err := creditWallet(tx, wallet, 0, models.Transaction{
+		GameID:      &game.ID,
+		GameType:    game.GameType,
+		Type:        "recovery",
+		Description: "Bet resumed - game recovered after restart",
+	}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	recovery := models.GameRecovery{
+		GameID:    game.ID,
+		RoundID:   game.RoundID,
+		UserID:    game.UserID,
+		Policy:    policy,
+		Action:    "resumed",
+		BetAmount: game.BetAmount,
+		Note:      "Returned to the round scheduler",
+	}
+	if err := tx.Create(&recovery).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return &recovery, nil
+}
+
+func saveRecovery(game *models.Game, policy, action string, amount float64, note string) (*models.GameRecovery, error) {
+	recovery := models.GameRecovery{
+		GameID:    game.ID,
+		RoundID:   game.RoundID,
+		UserID:    game.UserID,
+		Policy:    policy,
+		Action:    action,
+		BetAmount: game.BetAmount,
+		Amount:    amount,
+		Note:      note,
+	}
+	if err := config.DB.Create(&recovery).Error; err != nil {
+		return nil, err
+	}
+	return &recovery, nil
+}
+
+// closeRecoveredRound marks a round whose bets were all settled during
+// recovery as crashed so the scheduler opens a fresh one.
+func closeRecoveredRound(round *models.Round) {
+	now := time.Now()
+	result := config.DB.Model(&models.Round{}).
+		Where("id = ? AND status <> ?", round.ID, "crashed").
+		Updates(map[string]interface{}{"status": "crashed", "crashed_at": now})
+	if result.Error != nil {
+		log.Printf("Failed to close recovered round %d: %v", round.ID, result.Error)
+		return
+	}
+
+	round.Status = "crashed"
+	round.CrashedAt = &now
+	if err := recordRoundTotals(round); err != nil {
+		log.Printf("Failed to record totals of recovered round %d: %v", round.ID, err)
+	}
+}
@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/games/roulette"
+	"casino_api_go/models"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RouletteBetRequest struct {
+	Type    string  `json:"type" binding:"required,oneof=straight split street corner dozen column red black odd even"`
+	Numbers []int   `json:"numbers"`
+	Amount  float64 `json:"amount" binding:"required,gt=0"`
+}
+
+type SpinRouletteRequest struct {
+	Bets []RouletteBetRequest `json:"bets" binding:"required,min=1,max=50,dive"`
+}
+
+// rouletteResult derives the winning pocket from the seed, the same way the
+// verify endpoint recomputes it.
+func rouletteResult(serverSeed, clientSeed string, nonce uint64) int {
+	return roulette.Pocket(fairness.Float(fairness.GameHash(serverSeed, clientSeed, nonce)))
+}
+
+// rouletteGame spins the wheel once for a whole bet slip. The total stake
+// is debited in one go and every bet line is then settled on its own.
+type rouletteGame struct{}
+
+type rouletteSlip struct {
+	bets    []roulette.Bet
+	covered [][]int
+}
+
+func (rouletteGame) Info() GameInfo {
+	return GameInfo{
+		Type:        "roulette",
+		Name:        "Roulette",
+		PlayPath:    "/spin",
+		HistoryPath: "/spins",
+		HistoryKey:  "spins",
+		Event:       "roulette_spin",
+	}
+}
+
+func (rouletteGame) ValidateBet(c *gin.Context, settings models.GameSettings) (*GameBet, error) {
+	var req SpinRouletteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, newGameError(http.StatusBadRequest, "Invalid request data: %s", err.Error())
+	}
+
+	var slip rouletteSlip
+	var totalStake float64
+	for i, line := range req.Bets {
+		numbers, err := roulette.Covered(line.Type, line.Numbers)
+		if err != nil {
+			return nil, newGameError(http.StatusBadRequest, "Invalid bet line %d: %s", i+1, err.Error())
+		}
+		slip.bets = append(slip.bets, roulette.Bet{Type: line.Type, Numbers: line.Numbers, Amount: line.Amount})
+		slip.covered = append(slip.covered, numbers)
+		totalStake += line.Amount
+	}
+
+	maxPayout, _ := roulette.MaxPayout(slip.bets)
+
+	return &GameBet{
+		Amount:      totalStake,
+		MinBet:      settings.MinBetAmount,
+		MaxBet:      settings.MaxBetAmount,
+		MaxPayout:   maxPayout,
+		Description: fmt.Sprintf("Bet placed for roulette (%d bet lines)", len(slip.bets)),
+		Params:      slip,
+	}, nil
+}
+
+func (rouletteGame) Resolve(tx *gorm.DB, bet *GameBet, seed GameSeed) (*GameOutcome, error) {
+	slip := bet.Params.(rouletteSlip)
+	result := rouletteResult(seed.ServerSeed.Seed, seed.ClientSeed, seed.Nonce)
+
+	spin := models.RouletteSpin{
+		UserID:         bet.UserID,
+		Result:         result,
+		Color:          roulette.Color(result),
+		TotalStake:     bet.Amount,
+		ServerSeedID:   &seed.ServerSeed.ID,
+		ServerSeedHash: seed.ServerSeed.Hash,
+		ClientSeed:     seed.ClientSeed,
+		Nonce:          seed.Nonce,
+	}
+	for i, line := range slip.bets {
+		record := models.RouletteBet{
+			UserID:  bet.UserID,
+			Type:    line.Type,
+			Numbers: encodeTiles(line.Numbers),
+			Covered: encodeTiles(slip.covered[i]),
+			Amount:  line.Amount,
+		}
+		if roulette.Wins(slip.covered[i], result) {
+			record.Won = true
+			record.Payout = roulette.Payout(line.Amount, slip.covered[i])
+			spin.TotalPayout += record.Payout
+		}
+		spin.Bets = append(spin.Bets, record)
+	}
+
+	if err := tx.Create(&spin).Error; err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to record roulette spin")
+	}
+	spin.ServerSeed = seed.ServerSeed
+
+	return &GameOutcome{
+		RecordID:        spin.ID,
+		Record:          spin,
+		Message:         fmt.Sprintf("Ball landed on %d %s", result, spin.Color),
+		LossDescription: fmt.Sprintf("Roulette lost - ball landed on %d", result),
+	}, nil
+}
+
+func (rouletteGame) Settle(outcome *GameOutcome) []models.Transaction {
+	spin := outcome.Record.(models.RouletteSpin)
+
+	var settlements []models.Transaction
+	for _, line := range spin.Bets {
+		if !line.Won {
+			continue
+		}
+		settlements = append(settlements, models.Transaction{
+			Type:        "win",
+			Amount:      line.Payout,
+			Description: fmt.Sprintf("Roulette %s bet won on %d (bet line %d)", line.Type, spin.Result, line.ID),
+		})
+	}
+	return settlements
+}
+
+func (rouletteGame) Describe(outcome *GameOutcome) gin.H {
+	return gin.H{
+		"spin": rouletteSpinData(outcome.Record.(models.RouletteSpin)),
+	}
+}
+
+func (rouletteGame) History(_ *gin.Context, userID uint, limit int) ([]gin.H, error) {
+	var spins []models.RouletteSpin
+	if err := config.DB.Preload("ServerSeed").Preload("Bets").Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&spins).Error; err != nil {
+		return nil, err
+	}
+
+	var spinData []gin.H
+	for _, spin := range spins {
+		spinData = append(spinData, rouletteSpinData(spin))
+	}
+	return spinData, nil
+}
+
+func (rouletteGame) Routes(group *gin.RouterGroup) {
+	group.GET("/spin/:id/verify", VerifyRouletteSpin)
+}
+
+func rouletteSpinData(spin models.RouletteSpin) gin.H {
+	var betData []gin.H
+	for _, bet := range spin.Bets {
+		betData = append(betData, gin.H{
+			"id":      bet.ID,
+			"type":    bet.Type,
+			"numbers": decodeTiles(bet.Numbers),
+			"covered": decodeTiles(bet.Covered),
+			"amount":  bet.Amount,
+			"won":     bet.Won,
+			"payout":  bet.Payout,
+		})
+	}
+
+	data := gin.H{
+		"id":           spin.ID,
+		"result":       spin.Result,
+		"color":        spin.Color,
+		"total_stake":  spin.TotalStake,
+		"total_payout": spin.TotalPayout,
+		"bets":         betData,
+		"created_at":   spin.CreatedAt,
+	}
+
+	fairnessData := gin.H{
+		"server_seed_hash": spin.ServerSeedHash,
+		"client_seed":      spin.ClientSeed,
+		"nonce":            spin.Nonce,
+	}
+	if spin.ServerSeed != nil {
+		fairnessData["server_seed"] = spin.ServerSeed.Seed
+	}
+	data["fairness"] = fairnessData
+
+	return data
+}
+
+func VerifyRouletteSpin(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	spinID := c.Param("id")
+
+	var spin models.RouletteSpin
+	if err := config.DB.Preload("ServerSeed").Preload("Bets").First(&spin, spinID).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Roulette spin not found",
+		})
+		return
+	}
+
+	if spin.UserID != userID {
+		c.JSON(http.StatusForbidden, GameResponse{
+			Success: false,
+			Message: "Access denied",
+		})
+		return
+	}
+
+	if spin.ServerSeed == nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Roulette spin was not played with a provably fair seed",
+		})
+		return
+	}
+
+	serverSeed := spin.ServerSeed
+	result := rouletteResult(serverSeed.Seed, spin.ClientSeed, spin.Nonce)
+	seedMatches := fairness.HashSeed(serverSeed.Seed) == spin.ServerSeedHash
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Roulette spin verification retrieved successfully",
+		Data: gin.H{
+			"spin": rouletteSpinData(spin),
+			"fairness": gin.H{
+				"server_seed":      serverSeed.Seed,
+				"server_seed_hash": spin.ServerSeedHash,
+				"client_seed":      spin.ClientSeed,
+				"nonce":            spin.Nonce,
+				"computed_result":  result,
+				"verified":         seedMatches && result == spin.Result,
+			},
+		},
+	})
+}
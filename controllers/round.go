@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/models"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	bettingWindow = 5 * time.Second
+	roundCooldown = 3 * time.Second
+
+	defaultRoundClientSeed = "casino_api_go:crash"
+)
+
+func roundClientSeed() string {
+	if seed := os.Getenv("ROUND_CLIENT_SEED"); seed != "" {
+		return seed
+	}
+	return defaultRoundClientSeed
+}
+
+func StartRoundScheduler() {
+	crashWorkerOnce.Do(func() {
+		recoverActiveGames()
+		scheduler.resume()
+		scheduleTimedEvents()
+		go scheduler.run()
+		go runLiveTicks()
+	})
+}
+
+// roundMultiplier is the current multiplier of a round on the curve it was
+// opened with.
+func roundMultiplier(round *models.Round) float64 {
+	if round == nil || round.StartedAt == nil {
+		return 1.0
+	}
+	settings, err := roundSettings(round)
+	if err != nil {
+		return 1.0
+	}
+	return calculateCurrentMultiplier(*round.StartedAt, settings)
+}
+
+func roundElapsed(round *models.Round) float64 {
+	if round == nil || round.StartedAt == nil {
+		return 0
+	}
+	return time.Since(*round.StartedAt).Seconds()
+}
+
+func openRound(settings models.GameSettings) (*models.Round, error) {
+	if err := ensureSeedPool(); err != nil {
+		return nil, err
+	}
+
+	tx := config.DB.Begin()
+	round := models.Round{
+		Status:           "betting",
+		BettingEndsAt:    time.Now().Add(bettingWindow),
+		ClientSeed:       roundClientSeed(),
+		SettingsSnapshot: snapshotSettings(settings),
+	}
+	if err := tx.Create(&round).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	serverSeed, err := takeServerSeed(tx, "round_id", round.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := tx.Model(serverSeed).Update("used_at", now).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	round.ServerSeedID = &serverSeed.ID
+	round.ServerSeedHash = serverSeed.Hash
+	round.CrashPoint = fairness.CrashPoint(fairness.GameHash(serverSeed.Seed, round.ClientSeed, 0), crashParams(settings))
+	if err := tx.Save(&round).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	tx.Commit()
+	return &round, nil
+}
+
+func startRound(round *models.Round) error {
+	now := time.Now()
+	result := config.DB.Model(&models.Round{}).
+		Where("id = ? AND status = ?", round.ID, "betting").
+		Updates(map[string]interface{}{"status": "running", "started_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 1 {
+		round.Status = "running"
+		round.StartedAt = &now
+	}
+	return nil
+}
+
+func crashRound(round *models.Round) error {
+	now := time.Now()
+	result := config.DB.Model(&models.Round{}).
+		Where("id = ? AND status = ?", round.ID, "running").
+		Updates(map[string]interface{}{"status": "crashed", "crashed_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	round.Status = "crashed"
+	round.CrashedAt = &now
+
+	activeGamesMux.Lock()
+	games := make(map[uint]*models.Game)
+	for gameID, game := range activeGames {
+		if game.RoundID != nil && *game.RoundID == round.ID {
+			games[gameID] = game
+			delete(activeGames, gameID)
+		}
+	}
+	activeGamesMux.Unlock()
+
+	// A bet is registered in activeGames only after its transaction commits,
+	// and an instant crash can come before that. Its row is already stored,
+	// since betting holds the round until it commits, so the round's open
+	// games are also read from the database.
+	var stored []models.Game
+	if err := config.DB.Where("round_id = ? AND status = ?", round.ID, "active").Find(&stored).Error; err != nil {
+		log.Printf("Failed to load open games of round %d: %v", round.ID, err)
+	}
+	for i := range stored {
+		if _, ok := games[stored[i].ID]; !ok {
+			games[stored[i].ID] = &stored[i]
+		}
+	}
+
+	for _, game := range games {
+		_, response := completeGame(game, "auto_crash")
+		if response != nil && !response.Success && response.Message != gameAlreadySettled {
+			log.Printf("Failed to settle game %d of round %d: %s", game.ID, round.ID, response.Message)
+		}
+	}
+
+	return recordRoundTotals(round)
+}
+
+func recordRoundTotals(round *models.Round) error {
+	var totals struct {
+		TotalBets    int64
+		TotalWagered float64
+		TotalPayout  float64
+	}
+	if err := config.DB.Model(&models.Game{}).
+		Select("COUNT(*) AS total_bets, COALESCE(SUM(bet_amount), 0) AS total_wagered, COALESCE(SUM(win_amount), 0) AS total_payout").
+		Where("round_id = ? AND status <> ?", round.ID, "refunded").
+		Scan(&totals).Error; err != nil {
+		return err
+	}
+
+	round.TotalBets = totals.TotalBets
+	round.TotalWagered = totals.TotalWagered
+	round.TotalPayout = totals.TotalPayout
+
+	return config.DB.Model(round).Updates(map[string]interface{}{
+		"total_bets":    totals.TotalBets,
+		"total_wagered": totals.TotalWagered,
+		"total_payout":  totals.TotalPayout,
+	}).Error
+}
+
+func roundData(round models.Round) gin.H {
+	data := gin.H{
+		"id":               round.ID,
+		"status":           round.Status,
+		"betting_ends_at":  round.BettingEndsAt,
+		"started_at":       round.StartedAt,
+		"server_seed_hash": round.ServerSeedHash,
+		"client_seed":      round.ClientSeed,
+	}
+
+	switch round.Status {
+	case "running":
+		data["multiplier"] = roundMultiplier(&round)
+		data["elapsed_time"] = roundElapsed(&round)
+	case "crashed":
+		if round.ServerSeed != nil {
+			data["server_seed"] = round.ServerSeed.Seed
+		}
+		data["crash_point"] = round.CrashPoint
+		data["crashed_at"] = round.CrashedAt
+		data["total_bets"] = round.TotalBets
+		data["total_wagered"] = round.TotalWagered
+		data["total_payout"] = round.TotalPayout
+	}
+
+	return data
+}
+
+func GetCurrentRound(c *gin.Context) {
+	var round models.Round
+	if err := config.DB.Order("id DESC").First(&round).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "No round available yet",
+		})
+		return
+	}
+
+	var totalBets int64
+	config.DB.Model(&models.Game{}).Where("round_id = ?", round.ID).Count(&totalBets)
+
+	data := roundData(round)
+	data["total_bets"] = totalBets
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Current round retrieved successfully",
+		Data: gin.H{
+			"round": data,
+		},
+	})
+}
+
+func GetRoundHistory(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var rounds []models.Round
+	if err := config.DB.Preload("ServerSeed").Where("status = ?", "crashed").Order("id DESC").Limit(limit).Find(&rounds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to retrieve rounds",
+		})
+		return
+	}
+
+	var roundsData []gin.H
+	for _, round := range rounds {
+		roundsData = append(roundsData, roundData(round))
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Rounds retrieved successfully",
+		Data: gin.H{
+			"rounds": roundsData,
+		},
+	})
+}
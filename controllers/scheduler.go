@@ -0,0 +1,473 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/models"
+	"container/heap"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const liveTickInterval = 100 * time.Millisecond
+
+type eventKind int
+
+const (
+	eventOpenRound eventKind = iota
+	eventStartRound
+	eventAutoCashout
+	eventCrashRound
+	eventMaintenanceNotice
+	eventMaintenanceStart
+	eventMaintenanceEnd
+	eventSettingsChange
+)
+
+type scheduledEvent struct {
+	at      time.Time
+	kind    eventKind
+	roundID uint
+	gameID  uint
+	target  float64
+	// recordID is the maintenance window or scheduled settings change a
+	// timed event belongs to.
+	recordID uint
+	index    int
+}
+
+// eventQueue is a min-heap of scheduled events ordered by fire time.
+type eventQueue []*scheduledEvent
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+
+func (q eventQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *eventQueue) Push(x interface{}) {
+	event := x.(*scheduledEvent)
+	event.index = len(*q)
+	*q = append(*q, event)
+}
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	event := old[n-1]
+	old[n-1] = nil
+	event.index = -1
+	*q = old[:n-1]
+	return event
+}
+
+// crashScheduler owns the in-memory round timeline. Crash and auto cashout
+// times are computed once from the curve and fired from a min-heap, so the
+// database is only touched when something actually happens.
+type crashScheduler struct {
+	mu    sync.Mutex
+	queue eventQueue
+	round *models.Round
+	wake  chan struct{}
+}
+
+var (
+	scheduler = &crashScheduler{wake: make(chan struct{}, 1)}
+
+	settingsCache    *models.GameSettings
+	settingsCacheMux sync.RWMutex
+)
+
+func loadGameSettings() (models.GameSettings, error) {
+	settingsCacheMux.RLock()
+	cached := settingsCache
+	settingsCacheMux.RUnlock()
+	if cached != nil {
+		return *cached, nil
+	}
+
+	var settings models.GameSettings
+	if err := config.DB.Order("id DESC").First(&settings).Error; err != nil {
+		return settings, err
+	}
+
+	settingsCacheMux.Lock()
+	settingsCache = &settings
+	settingsCacheMux.Unlock()
+
+	return settings, nil
+}
+
+// refreshGameSettings drops the cached settings. Rounds and games that are
+// already open keep playing with their snapshot.
+func refreshGameSettings() {
+	settingsCacheMux.Lock()
+	settingsCache = nil
+	settingsCacheMux.Unlock()
+}
+
+// snapshotSettings encodes the settings a round or game is played with.
+func snapshotSettings(settings models.GameSettings) string {
+	encoded, _ := json.Marshal(settings)
+	return string(encoded)
+}
+
+func decodeSettingsSnapshot(snapshot string) (models.GameSettings, error) {
+	var settings models.GameSettings
+	err := json.Unmarshal([]byte(snapshot), &settings)
+	return settings, err
+}
+
+// snapshotOrCurrent decodes a settings snapshot. Rows created before
+// snapshots existed fall back to the live settings.
+func snapshotOrCurrent(snapshot string) (models.GameSettings, error) {
+	if snapshot != "" {
+		if settings, err := decodeSettingsSnapshot(snapshot); err == nil {
+			return settings, nil
+		}
+	}
+	return loadGameSettings()
+}
+
+func roundSettings(round *models.Round) (models.GameSettings, error) {
+	if round == nil {
+		return loadGameSettings()
+	}
+	return snapshotOrCurrent(round.SettingsSnapshot)
+}
+
+func gameSettings(game *models.Game) (models.GameSettings, error) {
+	if game.SettingsSnapshot == "" && game.Round != nil {
+		return roundSettings(game.Round)
+	}
+	return snapshotOrCurrent(game.SettingsSnapshot)
+}
+
+func (s *crashScheduler) push(event *scheduledEvent) {
+	s.mu.Lock()
+	heap.Push(&s.queue, event)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *crashScheduler) removeWhere(match func(*scheduledEvent) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.queue[:0]
+	for _, event := range s.queue {
+		if !match(event) {
+			kept = append(kept, event)
+		}
+	}
+	s.queue = kept
+	for i, event := range s.queue {
+		event.index = i
+	}
+	heap.Init(&s.queue)
+}
+
+func (s *crashScheduler) currentRound() *models.Round {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.round == nil {
+		return nil
+	}
+	round := *s.round
+	return &round
+}
+
+func (s *crashScheduler) roundByID(roundID *uint) *models.Round {
+	round := s.currentRound()
+	if round == nil || roundID == nil || round.ID != *roundID {
+		return nil
+	}
+	return round
+}
+
+func (s *crashScheduler) setRound(round *models.Round) {
+	s.mu.Lock()
+	if round == nil {
+		s.round = nil
+	} else {
+		copied := *round
+		s.round = &copied
+	}
+	s.mu.Unlock()
+}
+
+func (s *crashScheduler) run() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		wait := time.Hour
+		if len(s.queue) > 0 {
+			wait = time.Until(s.queue[0].at)
+		}
+		s.mu.Unlock()
+
+		if wait <= 0 {
+			s.fireDue()
+			continue
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *crashScheduler) fireDue() {
+	now := time.Now()
+	var due []*scheduledEvent
+
+	s.mu.Lock()
+	for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+		due = append(due, heap.Pop(&s.queue).(*scheduledEvent))
+	}
+	s.mu.Unlock()
+
+	for _, event := range due {
+		s.handle(event)
+	}
+}
+
+func (s *crashScheduler) handle(event *scheduledEvent) {
+	switch event.kind {
+	case eventOpenRound:
+		s.handleOpenRound()
+	case eventStartRound:
+		s.handleStartRound(event.roundID)
+	case eventAutoCashout:
+		s.handleAutoCashout(event)
+	case eventCrashRound:
+		s.handleCrashRound(event.roundID)
+	case eventMaintenanceNotice, eventMaintenanceStart, eventMaintenanceEnd:
+		handleMaintenanceEvent(event)
+	case eventSettingsChange:
+		applyScheduledSettingsChange(event.recordID)
+	}
+}
+
+func (s *crashScheduler) handleOpenRound() {
+	settings, err := loadGameSettings()
+	if err != nil {
+		s.push(&scheduledEvent{at: time.Now().Add(roundCooldown), kind: eventOpenRound})
+		return
+	}
+
+	round, err := openRound(settings)
+	if err != nil {
+		log.Printf("Failed to open round: %v", err)
+		s.push(&scheduledEvent{at: time.Now().Add(roundCooldown), kind: eventOpenRound})
+		return
+	}
+
+	s.setRound(round)
+	s.push(&scheduledEvent{at: round.BettingEndsAt, kind: eventStartRound, roundID: round.ID})
+	hub.broadcast("round_betting", roundData(*round), false)
+}
+
+func (s *crashScheduler) handleStartRound(roundID uint) {
+	round := s.roundByID(&roundID)
+	if round == nil {
+		return
+	}
+
+	if err := startRound(round); err != nil {
+		log.Printf("Failed to start round %d: %v", round.ID, err)
+		s.push(&scheduledEvent{at: time.Now().Add(liveTickInterval), kind: eventStartRound, roundID: roundID})
+		return
+	}
+
+	s.setRound(round)
+	s.scheduleRoundEvents(round)
+
+	hub.broadcast("round_started", roundData(*round), false)
+}
+
+// scheduleRoundEvents computes the crash time and every auto cashout time of a
+// running round once, from the curve's inverse.
+func (s *crashScheduler) scheduleRoundEvents(round *models.Round) {
+	settings, err := roundSettings(round)
+	if err != nil || round.StartedAt == nil {
+		return
+	}
+
+	growth := gameCurve(settings)
+	crashAt := round.StartedAt.Add(secondsToDuration(growth.TimeTo(round.CrashPoint)))
+	s.push(&scheduledEvent{at: crashAt, kind: eventCrashRound, roundID: round.ID})
+
+	activeGamesMux.RLock()
+	var targets []*scheduledEvent
+	for _, game := range activeGames {
+		if game.RoundID == nil || *game.RoundID != round.ID {
+			continue
+		}
+		if target := cashoutTarget(game, settings); target != nil {
+			targets = append(targets, autoCashoutEvent(round, growth.TimeTo(*target), game.ID, *target))
+		}
+	}
+	activeGamesMux.RUnlock()
+
+	for _, event := range targets {
+		if event.target < round.CrashPoint {
+			s.push(event)
+		}
+	}
+}
+
+func autoCashoutEvent(round *models.Round, seconds float64, gameID uint, target float64) *scheduledEvent {
+	return &scheduledEvent{
+		at:      round.StartedAt.Add(secondsToDuration(seconds)),
+		kind:    eventAutoCashout,
+		roundID: round.ID,
+		gameID:  gameID,
+		target:  target,
+	}
+}
+
+// scheduleAutoCashout (re)schedules a single bet after its target, stake or
+// the max win cap changed. Bets of a round that has not started are picked up
+// by scheduleRoundEvents when it starts.
+func (s *crashScheduler) scheduleAutoCashout(game *models.Game) {
+	s.removeWhere(func(event *scheduledEvent) bool {
+		return event.kind == eventAutoCashout && event.gameID == game.ID
+	})
+
+	round := s.roundByID(game.RoundID)
+	if round == nil || round.Status != "running" {
+		return
+	}
+
+	settings, err := roundSettings(round)
+	if err != nil {
+		return
+	}
+
+	target := cashoutTarget(game, settings)
+	if target == nil || *target >= round.CrashPoint {
+		return
+	}
+
+	s.push(autoCashoutEvent(round, gameCurve(settings).TimeTo(*target), game.ID, *target))
+}
+
+func (s *crashScheduler) handleAutoCashout(event *scheduledEvent) {
+	activeGamesMux.Lock()
+	game, ok := activeGames[event.gameID]
+	if !ok {
+		activeGamesMux.Unlock()
+		return
+	}
+	settings, err := gameSettings(game)
+	if err != nil {
+		activeGamesMux.Unlock()
+		return
+	}
+	target := cashoutTarget(game, settings)
+	if target == nil || *target != event.target {
+		activeGamesMux.Unlock()
+		return
+	}
+	delete(activeGames, event.gameID)
+	activeGamesMux.Unlock()
+
+	stopReason := "auto_cashout"
+	if game.AutoCashoutAt == nil || *game.AutoCashoutAt > *target {
+		stopReason = "max_win"
+	}
+
+	_, response := completeGame(game, stopReason)
+	if response != nil && !response.Success && response.Message != gameAlreadySettled {
+		log.Printf("Failed to auto cashout game %d: %s", game.ID, response.Message)
+	}
+}
+
+func (s *crashScheduler) handleCrashRound(roundID uint) {
+	round := s.roundByID(&roundID)
+	if round == nil {
+		return
+	}
+
+	if err := crashRound(round); err != nil {
+		log.Printf("Failed to crash round %d: %v", round.ID, err)
+		s.push(&scheduledEvent{at: time.Now().Add(liveTickInterval), kind: eventCrashRound, roundID: roundID})
+		return
+	}
+	log.Printf("Round %d crashed at multiplier %.2fx", round.ID, round.CrashPoint)
+
+	s.setRound(round)
+	s.removeWhere(func(event *scheduledEvent) bool {
+		return event.roundID == round.ID
+	})
+	s.push(&scheduledEvent{at: time.Now().Add(roundCooldown), kind: eventOpenRound})
+
+	config.DB.Preload("ServerSeed").First(round, round.ID)
+	hub.broadcast("round_crashed", roundData(*round), false)
+}
+
+// resume picks up a round that was still open when the process stopped.
+func (s *crashScheduler) resume() {
+	var round models.Round
+	if err := config.DB.Where("status <> ?", "crashed").Order("id DESC").First(&round).Error; err != nil {
+		s.push(&scheduledEvent{at: time.Now(), kind: eventOpenRound})
+		return
+	}
+
+	s.setRound(&round)
+	switch round.Status {
+	case "betting":
+		s.push(&scheduledEvent{at: round.BettingEndsAt, kind: eventStartRound, roundID: round.ID})
+	case "running":
+		s.scheduleRoundEvents(&round)
+	}
+}
+
+// runLiveTicks pushes the multiplier of the running round to live clients. It
+// only reads in-memory state.
+func runLiveTicks() {
+	ticker := time.NewTicker(liveTickInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !hub.hasClients() {
+			continue
+		}
+
+		round := scheduler.currentRound()
+		if round == nil || round.Status != "running" {
+			continue
+		}
+
+		multiplier := roundMultiplier(round)
+		if multiplier >= round.CrashPoint {
+			continue
+		}
+
+		hub.broadcast("tick", gin.H{
+			"round_id":     round.ID,
+			"multiplier":   multiplier,
+			"elapsed_time": roundElapsed(round),
+		}, true)
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
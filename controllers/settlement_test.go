@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"bytes"
+	"casino_api_go/config"
+	"casino_api_go/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	testBalance     = 1000.0
+	testBet         = 10.0
+	testAutoCashout = 1.5
+	testRaces       = 20
+)
+
+// openTestDB connects to the MySQL database in TEST_DB_DSN. The settlement
+// guard is a conditional UPDATE under a row lock, so these tests need the real
+// database and are skipped without one. Point TEST_DB_DSN at a throwaway
+// database; the tables are migrated and rows are added to it.
+func openTestDB(t *testing.T) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set")
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Wallet{}, &models.ServerSeed{}, &models.Round{}, &models.Game{}, &models.GameCashout{}, &models.Transaction{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	config.DB = db
+	gin.SetMode(gin.TestMode)
+}
+
+// createRunningGame puts a crash bet with an auto cashout into a round that
+// has already climbed past it, so a manual stop, the auto cashout and the
+// crash would all pay the same win if each were let through.
+func createRunningGame(t *testing.T, userID uint) (*models.Game, *models.Round) {
+	t.Helper()
+
+	settings := models.GameSettings{
+		MaxMultiplier:   100,
+		MinBetAmount:    1,
+		MaxBetAmount:    1000,
+		MultiplierSpeed: 0.1,
+		IsActive:        true,
+		HouseEdge:       1,
+		Distribution:    "standard",
+		Curve:           "linear",
+	}
+	snapshot, _ := json.Marshal(settings)
+
+	startedAt := time.Now().Add(-20 * time.Second)
+	round := models.Round{
+		Status:           "running",
+		CrashPoint:       50,
+		BettingEndsAt:    startedAt,
+		StartedAt:        &startedAt,
+		SettingsSnapshot: string(snapshot),
+	}
+	if err := config.DB.Create(&round).Error; err != nil {
+		t.Fatalf("Failed to create round: %v", err)
+	}
+
+	autoCashout := testAutoCashout
+	game := models.Game{
+		UserID:           userID,
+		GameType:         "crash",
+		BetAmount:        testBet,
+		Multiplier:       1.0,
+		CrashPoint:       round.CrashPoint,
+		Status:           "active",
+		RoundID:          &round.ID,
+		SettingsSnapshot: string(snapshot),
+		AutoCashoutAt:    &autoCashout,
+	}
+	if err := config.DB.Create(&game).Error; err != nil {
+		t.Fatalf("Failed to create game: %v", err)
+	}
+
+	return &game, &round
+}
+
+func stopGameRequest(userID, gameID uint) int {
+	body, _ := json.Marshal(StopGameRequest{GameID: gameID})
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/casino/stop", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", userID)
+
+	StopGame(c)
+	return recorder.Code
+}
+
+// TestConcurrentSettlementPaysOnce races a manual stop, the auto cashout and
+// the round crash against the same game and checks that the game is settled
+// exactly once.
+func TestConcurrentSettlementPaysOnce(t *testing.T) {
+	openTestDB(t)
+
+	suffix := time.Now().UnixNano()
+	user := models.User{
+		Username: fmt.Sprintf("settle_%d", suffix),
+		Email:    fmt.Sprintf("settle_%d@example.com", suffix),
+		Password: "x",
+	}
+	if err := config.DB.Create(&user).Error; err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	wallet := models.Wallet{UserID: user.ID, Balance: testBalance, Currency: "IDR"}
+	if err := config.DB.Create(&wallet).Error; err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+
+	for i := 0; i < testRaces; i++ {
+		game, round := createRunningGame(t, user.ID)
+
+		activeGamesMux.Lock()
+		activeGames[game.ID] = game
+		activeGamesMux.Unlock()
+
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		var stopStatus int
+
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			<-start
+			stopStatus = stopGameRequest(user.ID, game.ID)
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			scheduler.handleAutoCashout(&scheduledEvent{kind: eventAutoCashout, roundID: round.ID, gameID: game.ID, target: testAutoCashout})
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			if err := crashRound(round); err != nil {
+				t.Errorf("Failed to crash round %d: %v", round.ID, err)
+			}
+		}()
+		close(start)
+		wg.Wait()
+
+		switch stopStatus {
+		case http.StatusOK, http.StatusConflict, http.StatusBadRequest:
+		default:
+			t.Errorf("Game %d: manual stop returned status %d", game.ID, stopStatus)
+		}
+
+		var wins, settlements int64
+		config.DB.Model(&models.Transaction{}).Where("game_id = ? AND type = ?", game.ID, "win").Count(&wins)
+		config.DB.Model(&models.Transaction{}).Where("game_id = ? AND type IN ?", game.ID, []string{"win", "loss"}).Count(&settlements)
+		if wins != 1 || settlements != 1 {
+			t.Errorf("Game %d: got %d win and %d settlement transactions, want exactly 1 win", game.ID, wins, settlements)
+		}
+
+		var settled models.Game
+		config.DB.First(&settled, game.ID)
+		if settled.Status != "won" || settled.WinAmount != testBet*testAutoCashout {
+			t.Errorf("Game %d: settled as %s with %.2f, want won with %.2f", game.ID, settled.Status, settled.WinAmount, testBet*testAutoCashout)
+		}
+	}
+
+	// Every game was placed without a bet debit, so the wallet must have been
+	// credited exactly one win per game and nothing else.
+	var credited models.Wallet
+	config.DB.First(&credited, wallet.ID)
+	want := testBalance + testRaces*testBet*testAutoCashout
+	if credited.Balance != want {
+		t.Errorf("Wallet balance is %.2f, want %.2f", credited.Balance, want)
+	}
+}
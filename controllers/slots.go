@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"casino_api_go/config"
+	"casino_api_go/fairness"
+	"casino_api_go/games/slots"
+	"casino_api_go/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SpinSlotsRequest struct {
+	MachineID uint    `json:"machine_id" binding:"required"`
+	BetAmount float64 `json:"bet_amount" binding:"required,gt=0"`
+}
+
+func slotConfigHash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseSlotMachine(machine models.SlotMachine) (*slots.Config, error) {
+	return slots.Parse([]byte(machine.Config), machine.Format)
+}
+
+// slotRNG feeds a spin, and the free spins it triggers, from consecutive
+// cursors of the same seed so the whole outcome can be replayed.
+func slotRNG(serverSeed, clientSeed string, nonce uint64) slots.RNG {
+	cursor := 0
+	return func() float64 {
+		r := fairness.Float(fairness.CursorHash(serverSeed, clientSeed, nonce, cursor))
+		cursor++
+		return r
+	}
+}
+
+// slotsGame plays one paid spin on an active machine, auto-playing any free
+// spins it awards, and settles the total in the same request. Wins above
+// the max win per bet of the game settings are capped rather than refused,
+// since a spin has no fixed maximum payout.
+type slotsGame struct{}
+
+type slotsBet struct {
+	machine models.SlotMachine
+	config  *slots.Config
+}
+
+func (slotsGame) Info() GameInfo {
+	return GameInfo{
+		Type:        "slots",
+		Name:        "Slots",
+		PlayPath:    "/spin",
+		HistoryPath: "/spins",
+		HistoryKey:  "spins",
+		Event:       "slot_spin",
+	}
+}
+
+func (slotsGame) ValidateBet(c *gin.Context, _ models.GameSettings) (*GameBet, error) {
+	var req SpinSlotsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, newGameError(http.StatusBadRequest, "Invalid request data: %s", err.Error())
+	}
+
+	var machine models.SlotMachine
+	if err := config.DB.Where("id = ? AND status = ?", req.MachineID, "active").First(&machine).Error; err != nil {
+		return nil, newGameError(http.StatusNotFound, "Slot machine not found or not active")
+	}
+
+	machineConfig, err := parseSlotMachine(machine)
+	if err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Slot machine config is invalid")
+	}
+
+	return &GameBet{
+		Amount:      req.BetAmount,
+		MinBet:      machineConfig.MinBet,
+		MaxBet:      machineConfig.MaxBet,
+		Description: fmt.Sprintf("Bet placed on slot machine %s", machine.Name),
+		Params:      slotsBet{machine: machine, config: machineConfig},
+	}, nil
+}
+
+func (slotsGame) Resolve(tx *gorm.DB, bet *GameBet, seed GameSeed) (*GameOutcome, error) {
+	params := bet.Params.(slotsBet)
+
+	outcome := params.config.Play(bet.Amount, slotRNG(seed.ServerSeed.Seed, seed.ClientSeed, seed.Nonce))
+	encodedOutcome, _ := json.Marshal(outcome)
+
+	spin := models.SlotSpin{
+		UserID:         bet.UserID,
+		MachineID:      params.machine.ID,
+		ConfigHash:     params.machine.ConfigHash,
+		BetAmount:      bet.Amount,
+		WinAmount:      outcome.TotalWin,
+		FreeSpins:      len(outcome.FreeSpins),
+		Outcome:        string(encodedOutcome),
+		ServerSeedID:   &seed.ServerSeed.ID,
+		ServerSeedHash: seed.ServerSeed.Hash,
+		ClientSeed:     seed.ClientSeed,
+		Nonce:          seed.Nonce,
+	}
+	if bet.Settings.MaxWinPerBet > 0 && spin.WinAmount > bet.Settings.MaxWinPerBet {
+		spin.WinAmount = bet.Settings.MaxWinPerBet
+		spin.Capped = true
+	}
+
+	if err := tx.Create(&spin).Error; err != nil {
+		return nil, newGameError(http.StatusInternalServerError, "Failed to record slot spin")
+	}
+	spin.ServerSeed = seed.ServerSeed
+	spin.Machine = &params.machine
+
+	return &GameOutcome{
+		RecordID:        spin.ID,
+		Record:          spin,
+		Message:         fmt.Sprintf("Spin won %.2f", spin.WinAmount),
+		LossDescription: fmt.Sprintf("Slots lost on %s", params.machine.Name),
+	}, nil
+}
+
+func (slotsGame) Settle(outcome *GameOutcome) []models.Transaction {
+	spin := outcome.Record.(models.SlotSpin)
+	if spin.WinAmount <= 0 {
+		return nil
+	}
+	return []models.Transaction{{
+		Type:        "win",
+		Amount:      spin.WinAmount,
+		Description: fmt.Sprintf("Slots won on %s (%d free spins)", spin.Machine.Name, spin.FreeSpins),
+	}}
+}
+
+func (slotsGame) Describe(outcome *GameOutcome) gin.H {
+	return gin.H{
+		"spin": slotSpinData(outcome.Record.(models.SlotSpin)),
+	}
+}
+
+func (slotsGame) History(_ *gin.Context, userID uint, limit int) ([]gin.H, error) {
+	var spins []models.SlotSpin
+	if err := config.DB.Preload("ServerSeed").Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&spins).Error; err != nil {
+		return nil, err
+	}
+
+	var spinData []gin.H
+	for _, spin := range spins {
+		spinData = append(spinData, slotSpinData(spin))
+	}
+	return spinData, nil
+}
+
+func (slotsGame) Routes(group *gin.RouterGroup) {
+	group.GET("/machines", GetSlotMachines)
+	group.GET("/spin/:id/verify", VerifySlotSpin)
+}
+
+func slotSpinData(spin models.SlotSpin) gin.H {
+	var outcome slots.Outcome
+	json.Unmarshal([]byte(spin.Outcome), &outcome)
+
+	data := gin.H{
+		"id":         spin.ID,
+		"machine_id": spin.MachineID,
+		"bet_amount": spin.BetAmount,
+		"win_amount": spin.WinAmount,
+		"capped":     spin.Capped,
+		"free_spins": spin.FreeSpins,
+		"outcome":    outcome,
+		"created_at": spin.CreatedAt,
+	}
+
+	fairnessData := gin.H{
+		"server_seed_hash": spin.ServerSeedHash,
+		"client_seed":      spin.ClientSeed,
+		"nonce":            spin.Nonce,
+		"config_hash":      spin.ConfigHash,
+	}
+	if spin.ServerSeed != nil {
+		fairnessData["server_seed"] = spin.ServerSeed.Seed
+	}
+	data["fairness"] = fairnessData
+
+	return data
+}
+
+func GetSlotMachines(c *gin.Context) {
+	var machines []models.SlotMachine
+	if err := config.DB.Where("status = ?", "active").Order("id ASC").Find(&machines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Failed to retrieve slot machines",
+		})
+		return
+	}
+
+	var machineData []gin.H
+	for _, machine := range machines {
+		machineConfig, err := parseSlotMachine(machine)
+		if err != nil {
+			continue
+		}
+		machineData = append(machineData, gin.H{
+			"id":            machine.ID,
+			"name":          machine.Name,
+			"rows":          machineConfig.Rows,
+			"reels":         len(machineConfig.Reels),
+			"symbols":       machineConfig.Symbols,
+			"paylines":      machineConfig.Paylines,
+			"free_spins":    machineConfig.FreeSpins,
+			"min_bet":       machineConfig.MinBet,
+			"max_bet":       machineConfig.MaxBet,
+			"simulated_rtp": machine.SimulatedRTP,
+			"config_hash":   machine.ConfigHash,
+		})
+	}
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Slot machines retrieved successfully",
+		Data: gin.H{
+			"machines": machineData,
+		},
+	})
+}
+
+func VerifySlotSpin(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	spinID := c.Param("id")
+
+	var spin models.SlotSpin
+	if err := config.DB.Preload("ServerSeed").Preload("Machine").First(&spin, spinID).Error; err != nil {
+		c.JSON(http.StatusNotFound, GameResponse{
+			Success: false,
+			Message: "Slot spin not found",
+		})
+		return
+	}
+
+	if spin.UserID != userID {
+		c.JSON(http.StatusForbidden, GameResponse{
+			Success: false,
+			Message: "Access denied",
+		})
+		return
+	}
+
+	if spin.ServerSeed == nil || spin.Machine == nil {
+		c.JSON(http.StatusBadRequest, GameResponse{
+			Success: false,
+			Message: "Slot spin was not played with a provably fair seed",
+		})
+		return
+	}
+
+	// A replay is only meaningful against the exact config the spin was
+	// played with.
+	if spin.Machine.ConfigHash != spin.ConfigHash {
+		c.JSON(http.StatusConflict, GameResponse{
+			Success: false,
+			Message: "Slot machine config has changed since this spin",
+		})
+		return
+	}
+
+	machineConfig, err := parseSlotMachine(*spin.Machine)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GameResponse{
+			Success: false,
+			Message: "Slot machine config is invalid",
+		})
+		return
+	}
+
+	serverSeed := spin.ServerSeed
+	outcome := machineConfig.Play(spin.BetAmount, slotRNG(serverSeed.Seed, spin.ClientSeed, spin.Nonce))
+	encodedOutcome, _ := json.Marshal(outcome)
+	seedMatches := fairness.HashSeed(serverSeed.Seed) == spin.ServerSeedHash
+
+	c.JSON(http.StatusOK, GameResponse{
+		Success: true,
+		Message: "Slot spin verification retrieved successfully",
+		Data: gin.H{
+			"spin": slotSpinData(spin),
+			"fairness": gin.H{
+				"server_seed":        serverSeed.Seed,
+				"server_seed_hash":   spin.ServerSeedHash,
+				"client_seed":        spin.ClientSeed,
+				"nonce":              spin.Nonce,
+				"config_hash":        spin.ConfigHash,
+				"computed_total_win": outcome.TotalWin,
+				"verified":           seedMatches && string(encodedOutcome) == spin.Outcome,
+			},
+		},
+	})
+}
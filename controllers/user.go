@@ -296,6 +296,7 @@ func Deposit(c *gin.Context) {
 			"transaction": gin.H{
 				"id":          transaction.ID,
 				"type":        transaction.Type,
+				"game_type":   transaction.GameType,
 				"amount":      transaction.Amount,
 				"balance":     transaction.Balance,
 				"description": transaction.Description,
@@ -406,6 +407,7 @@ func Withdraw(c *gin.Context) {
 			"transaction": gin.H{
 				"id":          transaction.ID,
 				"type":        transaction.Type,
+				"game_type":   transaction.GameType,
 				"amount":      transaction.Amount,
 				"balance":     transaction.Balance,
 				"description": transaction.Description,
@@ -428,6 +430,7 @@ func GetTransactionHistory(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	transactionType := c.Query("type")
+	gameType := c.Query("game_type")
 
 	offset := (page - 1) * limit
 
@@ -440,6 +443,10 @@ func GetTransactionHistory(c *gin.Context) {
 		query = query.Where("type = ?", transactionType)
 	}
 
+	if gameType != "" {
+		query = query.Where("game_type = ?", gameType)
+	}
+
 	query.Count(&total)
 	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&transactions).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, AuthResponse{
@@ -454,6 +461,7 @@ func GetTransactionHistory(c *gin.Context) {
 		transactionData = append(transactionData, gin.H{
 			"id":          transaction.ID,
 			"type":        transaction.Type,
+			"game_type":   transaction.GameType,
 			"amount":      transaction.Amount,
 			"balance":     transaction.Balance,
 			"description": transaction.Description,
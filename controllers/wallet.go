@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"casino_api_go/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errInsufficientBalance = errors.New("insufficient wallet balance")
+
+// lockWallet loads a user's wallet with a row lock held until tx ends, so
+// concurrent balance changes are applied one after another.
+func lockWallet(tx *gorm.DB, userID uint) (*models.Wallet, error) {
+	var wallet models.Wallet
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&wallet).Error; err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// debitWallet takes amount from a locked wallet and records entry with the
+// resulting balance.
+func debitWallet(tx *gorm.DB, wallet *models.Wallet, amount float64, entry models.Transaction) (*models.Transaction, error) {
+	if wallet.Balance < amount {
+		return nil, errInsufficientBalance
+	}
+	return applyWallet(tx, wallet, -amount, entry)
+}
+
+// creditWallet adds amount to a locked wallet and records entry with the
+// resulting balance. A zero amount only records the entry, as for losses.
+func creditWallet(tx *gorm.DB, wallet *models.Wallet, amount float64, entry models.Transaction) (*models.Transaction, error) {
+	return applyWallet(tx, wallet, amount, entry)
+}
+
+func applyWallet(tx *gorm.DB, wallet *models.Wallet, delta float64, entry models.Transaction) (*models.Transaction, error) {
+	if delta != 0 {
+		if err := tx.Model(wallet).Update("balance", wallet.Balance+delta).Error; err != nil {
+			return nil, err
+		}
+		wallet.Balance += delta
+	}
+
+	entry.UserID = wallet.UserID
+	entry.Amount = delta
+	entry.Balance = wallet.Balance
+	if err := tx.Create(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
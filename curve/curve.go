@@ -0,0 +1,163 @@
+// Package curve describes how the crash multiplier grows over time. Every
+// curve provides the forward function and its inverse so callers can compute
+// both the current multiplier and the moment a multiplier will be reached.
+package curve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	Linear      = "linear"
+	Exponential = "exponential"
+	Piecewise   = "piecewise"
+)
+
+type Curve interface {
+	// Multiplier returns the multiplier after elapsed seconds.
+	Multiplier(elapsed float64) float64
+	// TimeTo returns the elapsed seconds at which multiplier is reached.
+	TimeTo(multiplier float64) float64
+}
+
+// Point is a breakpoint of a piecewise curve.
+type Point struct {
+	Time       float64 `json:"time"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// New builds a curve. speed is the growth per second for linear curves and the
+// rate k of e^(k·t) for exponential curves. points is only used by piecewise
+// curves.
+func New(kind string, speed float64, points []Point) (Curve, error) {
+	switch kind {
+	case "", Linear:
+		if speed <= 0 {
+			return nil, errors.New("linear curve needs a positive speed")
+		}
+		return linear{speed: speed}, nil
+	case Exponential:
+		if speed <= 0 {
+			return nil, errors.New("exponential curve needs a positive rate")
+		}
+		return exponential{rate: speed}, nil
+	case Piecewise:
+		return newPiecewise(points)
+	default:
+		return nil, fmt.Errorf("unknown curve %q", kind)
+	}
+}
+
+// ParsePoints decodes the JSON list of breakpoints stored with the settings.
+func ParsePoints(raw string) ([]Point, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var points []Point
+	if err := json.Unmarshal([]byte(raw), &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+type linear struct {
+	speed float64
+}
+
+func (c linear) Multiplier(elapsed float64) float64 {
+	if elapsed <= 0 {
+		return 1
+	}
+	return 1 + elapsed*c.speed
+}
+
+func (c linear) TimeTo(multiplier float64) float64 {
+	if multiplier <= 1 {
+		return 0
+	}
+	return (multiplier - 1) / c.speed
+}
+
+type exponential struct {
+	rate float64
+}
+
+func (c exponential) Multiplier(elapsed float64) float64 {
+	if elapsed <= 0 {
+		return 1
+	}
+	return math.Exp(c.rate * elapsed)
+}
+
+func (c exponential) TimeTo(multiplier float64) float64 {
+	if multiplier <= 1 {
+		return 0
+	}
+	return math.Log(multiplier) / c.rate
+}
+
+// piecewise interpolates linearly between breakpoints that start at (0, 1) and
+// keeps the slope of the last segment after the final breakpoint.
+type piecewise struct {
+	points []Point
+}
+
+func newPiecewise(points []Point) (Curve, error) {
+	sorted := append([]Point(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+
+	if len(sorted) == 0 || sorted[0].Time != 0 {
+		sorted = append([]Point{{Time: 0, Multiplier: 1}}, sorted...)
+	}
+	if sorted[0].Multiplier != 1 {
+		return nil, errors.New("piecewise curve must start at multiplier 1.0")
+	}
+	if len(sorted) < 2 {
+		return nil, errors.New("piecewise curve needs at least one breakpoint after 0s")
+	}
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Time <= sorted[i-1].Time || sorted[i].Multiplier <= sorted[i-1].Multiplier {
+			return nil, errors.New("piecewise curve breakpoints must strictly increase in time and multiplier")
+		}
+	}
+
+	return piecewise{points: sorted}, nil
+}
+
+func (c piecewise) segment(i int) (Point, Point, float64) {
+	a, b := c.points[i], c.points[i+1]
+	return a, b, (b.Multiplier - a.Multiplier) / (b.Time - a.Time)
+}
+
+func (c piecewise) Multiplier(elapsed float64) float64 {
+	if elapsed <= 0 {
+		return 1
+	}
+	last := len(c.points) - 2
+	for i := 0; i <= last; i++ {
+		a, b, slope := c.segment(i)
+		if elapsed <= b.Time || i == last {
+			return a.Multiplier + (elapsed-a.Time)*slope
+		}
+	}
+	return c.points[len(c.points)-1].Multiplier
+}
+
+func (c piecewise) TimeTo(multiplier float64) float64 {
+	if multiplier <= 1 {
+		return 0
+	}
+	last := len(c.points) - 2
+	for i := 0; i <= last; i++ {
+		a, b, slope := c.segment(i)
+		if multiplier <= b.Multiplier || i == last {
+			return a.Time + (multiplier-a.Multiplier)/slope
+		}
+	}
+	return c.points[len(c.points)-1].Time
+}
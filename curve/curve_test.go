@@ -0,0 +1,98 @@
+package curve
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	points := []Point{{Time: 5, Multiplier: 2}, {Time: 10, Multiplier: 5}, {Time: 20, Multiplier: 25}}
+
+	tests := []struct {
+		kind   string
+		speed  float64
+		points []Point
+	}{
+		{Linear, 0.1, nil},
+		{"", 0.25, nil},
+		{Exponential, 0.06, nil},
+		{Piecewise, 0, points},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			growth, err := New(tt.kind, tt.speed, tt.points)
+			if err != nil {
+				t.Fatalf("New(%q) failed: %v", tt.kind, err)
+			}
+
+			for _, multiplier := range []float64{1.01, 1.5, 2, 3.7, 5, 10, 25, 100, 1000} {
+				elapsed := growth.TimeTo(multiplier)
+				if got := growth.Multiplier(elapsed); math.Abs(got-multiplier) > 1e-9*multiplier {
+					t.Errorf("Multiplier(TimeTo(%v)) = %v", multiplier, got)
+				}
+			}
+
+			for _, elapsed := range []float64{0.5, 5, 7.5, 10, 30, 60} {
+				multiplier := growth.Multiplier(elapsed)
+				if got := growth.TimeTo(multiplier); math.Abs(got-elapsed) > 1e-9*elapsed {
+					t.Errorf("TimeTo(Multiplier(%v)) = %v", elapsed, got)
+				}
+			}
+
+			if got := growth.Multiplier(0); got != 1 {
+				t.Errorf("Multiplier(0) = %v, want 1", got)
+			}
+			if got := growth.TimeTo(1); got != 0 {
+				t.Errorf("TimeTo(1) = %v, want 0", got)
+			}
+		})
+	}
+}
+
+func TestPiecewiseBreakpoints(t *testing.T) {
+	growth, err := New(Piecewise, 0, []Point{{Time: 10, Multiplier: 5}, {Time: 5, Multiplier: 2}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tests := []struct {
+		elapsed, multiplier float64
+	}{
+		{2.5, 1.5},
+		{5, 2},
+		{7.5, 3.5},
+		{10, 5},
+		// The last segment's slope carries on past the final breakpoint.
+		{12, 6.2},
+	}
+	for _, tt := range tests {
+		if got := growth.Multiplier(tt.elapsed); math.Abs(got-tt.multiplier) > 1e-9 {
+			t.Errorf("Multiplier(%v) = %v, want %v", tt.elapsed, got, tt.multiplier)
+		}
+	}
+}
+
+func TestNewRejectsInvalidCurves(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		speed  float64
+		points []Point
+	}{
+		{"linear without speed", Linear, 0, nil},
+		{"exponential without rate", Exponential, -1, nil},
+		{"piecewise without breakpoints", Piecewise, 0, nil},
+		{"piecewise not starting at 1", Piecewise, 0, []Point{{Time: 0, Multiplier: 1.5}, {Time: 5, Multiplier: 2}}},
+		{"piecewise going down", Piecewise, 0, []Point{{Time: 5, Multiplier: 3}, {Time: 10, Multiplier: 2}}},
+		{"unknown kind", "quadratic", 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.kind, tt.speed, tt.points); err == nil {
+				t.Errorf("New(%q) accepted an invalid curve", tt.kind)
+			}
+		})
+	}
+}
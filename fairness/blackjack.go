@@ -0,0 +1,21 @@
+package fairness
+
+// ShuffleShoe returns decks 52 card decks in draw order, shuffled with a
+// Fisher-Yates shuffle driven by CursorHash. Cards are numbered 0-51 in each
+// deck. A shoe that runs dry mid-hand is topped up with the next pass, which
+// continues the cursor where the previous pass stopped.
+func ShuffleShoe(serverSeed, clientSeed string, nonce uint64, decks, pass int) []int {
+	size := decks * 52
+	cards := make([]int, size)
+	for i := range cards {
+		cards[i] = i % 52
+	}
+
+	for i := 0; i < size-1; i++ {
+		r := Float(CursorHash(serverSeed, clientSeed, nonce, pass*size+i))
+		j := i + int(r*float64(size-i))
+		cards[i], cards[j] = cards[j], cards[i]
+	}
+
+	return cards
+}
@@ -0,0 +1,40 @@
+package fairness
+
+import "math"
+
+const (
+	DiceOver  = "over"
+	DiceUnder = "under"
+)
+
+// DiceRoll derives a roll between 0.00 and 99.99 from a game hash, giving
+// 10,000 equally likely outcomes.
+func DiceRoll(hash string) float64 {
+	return math.Floor(Float(hash)*10000) / 100
+}
+
+// DiceWinChance returns the win chance in percent of rolling over or under
+// target.
+func DiceWinChance(target float64, direction string) float64 {
+	if direction == DiceOver {
+		return math.Round((99.99-target)*100) / 100
+	}
+	return math.Round(target*100) / 100
+}
+
+// DiceWins reports whether roll beats target in the given direction.
+func DiceWins(roll, target float64, direction string) bool {
+	if direction == DiceOver {
+		return roll > target
+	}
+	return roll < target
+}
+
+// DiceMultiplier returns the payout multiplier for a win chance in percent
+// after the house edge, rounded down to four decimals.
+func DiceMultiplier(winChance, houseEdge float64) float64 {
+	if winChance <= 0 {
+		return 0
+	}
+	return math.Floor((100-houseEdge)/winChance*10000) / 10000
+}
@@ -0,0 +1,111 @@
+// Package fairness implements the provably fair scheme used to derive game
+// outcomes. Every result can be recomputed from the revealed server seed, the
+// player's client seed and the nonce, without access to the database.
+package fairness
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	DistributionStandard = "standard"
+	DistributionPareto   = "pareto"
+)
+
+// Params describes the crash point distribution. HouseEdge and
+// InstantCrashChance are percentages. Shape is the Pareto tail index and is
+// only used by DistributionPareto; the standard 1/(1-r) curve has a shape of 1.
+type Params struct {
+	MaxMultiplier      float64
+	HouseEdge          float64
+	InstantCrashChance float64
+	Distribution       string
+	Shape              float64
+}
+
+func (p Params) shape() float64 {
+	if p.Distribution == DistributionPareto && p.Shape > 0 {
+		return p.Shape
+	}
+	return 1
+}
+
+// GenerateSeed returns a new random 32-byte seed encoded as hex.
+func GenerateSeed() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashSeed returns the SHA-256 commitment of a seed. This is the value shown
+// to players before a bet is placed.
+func HashSeed(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// GameHash combines the seeds and nonce into the HMAC-SHA256 digest from
+// which a game outcome is derived.
+func GameHash(serverSeed, clientSeed string, nonce uint64) string {
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	fmt.Fprintf(mac, "%s:%d", clientSeed, nonce)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Float maps the first 52 bits of a game hash to a uniform value in [0, 1).
+func Float(hash string) float64 {
+	return floatAt(hash, 0)
+}
+
+func floatAt(hash string, offset int) float64 {
+	if len(hash) < offset+13 {
+		return 0
+	}
+	value, err := strconv.ParseUint(hash[offset:offset+13], 16, 64)
+	if err != nil {
+		return 0
+	}
+	return float64(value) / float64(uint64(1)<<52)
+}
+
+// CrashPoint derives the crash multiplier of a game hash, rounded down to two
+// decimals and capped at the maximum multiplier. A result of 1.00 is an
+// instant crash. The instant crash roll uses the second 52 bits of the hash so
+// it is independent of the multiplier roll.
+func CrashPoint(hash string, p Params) float64 {
+	if p.InstantCrashChance > 0 && floatAt(hash, 13) < p.InstantCrashChance/100 {
+		return 1.0
+	}
+
+	r := Float(hash)
+	raw := math.Pow(1-r, -1/p.shape())
+	crashPoint := math.Floor(raw*(1-p.HouseEdge/100)*100) / 100
+
+	if crashPoint < 1.0 {
+		crashPoint = 1.0
+	}
+	if crashPoint > p.MaxMultiplier {
+		crashPoint = p.MaxMultiplier
+	}
+
+	return crashPoint
+}
+
+// RTP returns the theoretical return to player, as a fraction, of a player who
+// always cashes out at target.
+func RTP(p Params, target float64) float64 {
+	if target < 1 || target >= p.MaxMultiplier {
+		return 0
+	}
+
+	survival := math.Min(1, math.Pow((1-p.HouseEdge/100)/target, p.shape()))
+	return target * survival * (1 - p.InstantCrashChance/100)
+}
@@ -0,0 +1,257 @@
+package fairness
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const (
+	testServerSeed = "3f1d6c0a9be24e7f8d55a1c2b7e09d4f6a3c8e1b2d7f9a0c5e4b3d2a1f0e9c8b"
+	testClientSeed = "client-seed"
+)
+
+// testHash builds a game hash whose first 13 hex digits drive the multiplier
+// roll and whose next 13 drive the instant crash roll.
+func testHash(roll, instant string) string {
+	hash := roll + instant
+	return hash + strings.Repeat("0", 64-len(hash))
+}
+
+func TestCrashPoint(t *testing.T) {
+	tests := []struct {
+		name   string
+		hash   string
+		params Params
+		want   float64
+	}{
+		{"lowest roll crashes instantly", testHash("0000000000000", "fffffffffffff"), Params{MaxMultiplier: 100, HouseEdge: 1}, 1},
+		{"half roll without edge", testHash("8000000000000", "fffffffffffff"), Params{MaxMultiplier: 100}, 2},
+		{"half roll with edge", testHash("8000000000000", "fffffffffffff"), Params{MaxMultiplier: 100, HouseEdge: 1}, 1.98},
+		{"three quarter roll with edge", testHash("c000000000000", "fffffffffffff"), Params{MaxMultiplier: 100, HouseEdge: 4}, 3.84},
+		{"capped at the max multiplier", testHash("fffffffffffff", "fffffffffffff"), Params{MaxMultiplier: 100, HouseEdge: 1}, 100},
+		{"instant crash roll hit", testHash("c000000000000", "0000000000000"), Params{MaxMultiplier: 100, InstantCrashChance: 1}, 1},
+		{"instant crash roll missed", testHash("c000000000000", "fffffffffffff"), Params{MaxMultiplier: 100, InstantCrashChance: 1}, 4},
+		{"pareto shape", testHash("c000000000000", "fffffffffffff"), Params{MaxMultiplier: 100, Distribution: DistributionPareto, Shape: 2}, 2},
+		{"pareto without shape is standard", testHash("c000000000000", "fffffffffffff"), Params{MaxMultiplier: 100, Distribution: DistributionPareto}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CrashPoint(tt.hash, tt.params); got != tt.want {
+				t.Errorf("CrashPoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		target float64
+		want   float64
+	}{
+		{"standard is the same at any target", Params{MaxMultiplier: 100, HouseEdge: 1}, 2, 0.99},
+		{"standard at a high target", Params{MaxMultiplier: 100, HouseEdge: 1}, 50, 0.99},
+		{"instant crash chance", Params{MaxMultiplier: 100, HouseEdge: 1, InstantCrashChance: 1}, 2, 0.9801},
+		{"pareto depends on the target", Params{MaxMultiplier: 100, Distribution: DistributionPareto, Shape: 2}, 2, 0.5},
+		{"target below 1", Params{MaxMultiplier: 100, HouseEdge: 1}, 0.5, 0},
+		{"target at the max multiplier", Params{MaxMultiplier: 100, HouseEdge: 1}, 100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RTP(tt.params, tt.target); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("RTP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRTPMatchesCrashPoints checks the closed form against the crash points
+// the game actually produces.
+func TestRTPMatchesCrashPoints(t *testing.T) {
+	const rounds = 200000
+	params := Params{MaxMultiplier: 1000, HouseEdge: 3, InstantCrashChance: 2}
+	target := 2.0
+
+	var returned float64
+	for nonce := uint64(0); nonce < rounds; nonce++ {
+		if CrashPoint(GameHash(testServerSeed, testClientSeed, nonce), params) > target {
+			returned += target
+		}
+	}
+
+	got := returned / rounds
+	want := RTP(params, target)
+	if math.Abs(got-want) > 0.01 {
+		t.Errorf("simulated RTP %v, want about %v", got, want)
+	}
+}
+
+func TestDiceRoll(t *testing.T) {
+	tests := []struct {
+		hash string
+		want float64
+	}{
+		{testHash("0000000000000", ""), 0},
+		{testHash("8000000000000", ""), 50},
+		{testHash("fffffffffffff", ""), 99.99},
+	}
+
+	for _, tt := range tests {
+		if got := DiceRoll(tt.hash); got != tt.want {
+			t.Errorf("DiceRoll(%s) = %v, want %v", tt.hash[:13], got, tt.want)
+		}
+	}
+}
+
+func TestDiceOdds(t *testing.T) {
+	tests := []struct {
+		target     float64
+		direction  string
+		roll       float64
+		chance     float64
+		multiplier float64
+		wins       bool
+	}{
+		{49.5, DiceOver, 49.51, 50.49, 1.9607, true},
+		{49.5, DiceOver, 49.5, 50.49, 1.9607, false},
+		{49.5, DiceUnder, 49.49, 49.5, 2, true},
+		{49.5, DiceUnder, 49.5, 49.5, 2, false},
+		{2, DiceUnder, 0, 2, 49.5, true},
+	}
+
+	for _, tt := range tests {
+		chance := DiceWinChance(tt.target, tt.direction)
+		if chance != tt.chance {
+			t.Errorf("DiceWinChance(%v, %s) = %v, want %v", tt.target, tt.direction, chance, tt.chance)
+		}
+		if got := DiceMultiplier(chance, 1); got != tt.multiplier {
+			t.Errorf("DiceMultiplier(%v, 1) = %v, want %v", chance, got, tt.multiplier)
+		}
+		if got := DiceWins(tt.roll, tt.target, tt.direction); got != tt.wins {
+			t.Errorf("DiceWins(%v, %v, %s) = %v, want %v", tt.roll, tt.target, tt.direction, got, tt.wins)
+		}
+	}
+}
+
+// checkDraw fails unless numbers holds want sorted, distinct values in
+// [low, high].
+func checkDraw(t *testing.T, numbers []int, want, low, high int) {
+	t.Helper()
+
+	if len(numbers) != want {
+		t.Fatalf("got %d numbers, want %d", len(numbers), want)
+	}
+	if !sort.IntsAreSorted(numbers) {
+		t.Errorf("numbers %v are not sorted", numbers)
+	}
+	for i, n := range numbers {
+		if n < low || n > high {
+			t.Errorf("number %d is outside %d-%d", n, low, high)
+		}
+		if i > 0 && numbers[i-1] == n {
+			t.Errorf("number %d repeats", n)
+		}
+	}
+}
+
+func TestMinePositions(t *testing.T) {
+	for _, count := range []int{1, 3, 12, 24} {
+		mines := MinePositions(testServerSeed, testClientSeed, 7, count)
+		checkDraw(t, mines, count, 0, MinesTiles-1)
+
+		again := MinePositions(testServerSeed, testClientSeed, 7, count)
+		for i := range mines {
+			if mines[i] != again[i] {
+				t.Fatalf("MinePositions with %d mines is not deterministic: %v and %v", count, mines, again)
+			}
+		}
+	}
+}
+
+func TestMinesMultiplier(t *testing.T) {
+	tests := []struct {
+		mines, revealed int
+		houseEdge       float64
+		want            float64
+	}{
+		{3, 0, 1, 1},
+		{1, 1, 0, 1.0416},
+		{3, 1, 1, 1.125},
+		{24, 1, 1, 24.75},
+	}
+
+	for _, tt := range tests {
+		if got := MinesMultiplier(tt.mines, tt.revealed, tt.houseEdge); got != tt.want {
+			t.Errorf("MinesMultiplier(%d, %d, %v) = %v, want %v", tt.mines, tt.revealed, tt.houseEdge, got, tt.want)
+		}
+	}
+}
+
+func TestKenoDraw(t *testing.T) {
+	drawn := KenoDraw(testServerSeed, testClientSeed, 1)
+	checkDraw(t, drawn, KenoDrawSize, 1, KenoNumbers)
+
+	again := KenoDraw(testServerSeed, testClientSeed, 1)
+	for i := range drawn {
+		if drawn[i] != again[i] {
+			t.Fatalf("KenoDraw is not deterministic: %v and %v", drawn, again)
+		}
+	}
+
+	other := KenoDraw(testServerSeed, testClientSeed, 2)
+	same := true
+	for i := range drawn {
+		if drawn[i] != other[i] {
+			same = false
+		}
+	}
+	if same {
+		t.Errorf("KenoDraw gave the same numbers for nonce 1 and 2: %v", drawn)
+	}
+}
+
+func TestPlinkoPath(t *testing.T) {
+	for _, rows := range []int{8, 12, 16} {
+		path, slot := PlinkoPath(testServerSeed, testClientSeed, 3, rows)
+		if len(path) != rows {
+			t.Fatalf("PlinkoPath with %d rows has %d steps", rows, len(path))
+		}
+
+		rights := 0
+		for _, step := range path {
+			if step != 0 && step != 1 {
+				t.Fatalf("step %d is neither left nor right", step)
+			}
+			rights += step
+		}
+		if slot != rights {
+			t.Errorf("PlinkoPath with %d rows landed in slot %d after %d right steps", rows, slot, rights)
+		}
+	}
+}
+
+func TestPlinkoRTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		multipliers []float64
+		want        float64
+	}{
+		{"flat table", []float64{1, 1, 1, 1, 1, 1, 1, 1, 1}, 1},
+		{"edges only", []float64{2, 0, 2}, 1},
+		{"middle only", []float64{0, 1, 0}, 0.5},
+		{"no rows", []float64{5}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlinkoRTP(tt.multipliers); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PlinkoRTP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
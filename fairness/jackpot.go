@@ -0,0 +1,12 @@
+package fairness
+
+// JackpotRoll derives the jackpot draw of a bet from its game hash, uniform
+// in [0, 100). It keeps full precision because trigger chances are tiny.
+func JackpotRoll(hash string) float64 {
+	return Float(hash) * 100
+}
+
+// JackpotWins reports whether a roll hits a trigger chance given in percent.
+func JackpotWins(roll, chance float64) bool {
+	return roll < chance
+}
@@ -0,0 +1,29 @@
+package fairness
+
+import (
+	"sort"
+)
+
+const (
+	KenoNumbers  = 80
+	KenoDrawSize = 20
+)
+
+// KenoDraw shuffles the numbers 1 to 80 with a Fisher-Yates shuffle driven by
+// CursorHash and returns the first 20, sorted, as the drawn numbers.
+func KenoDraw(serverSeed, clientSeed string, nonce uint64) []int {
+	numbers := make([]int, KenoNumbers)
+	for i := range numbers {
+		numbers[i] = i + 1
+	}
+
+	for i := 0; i < KenoDrawSize; i++ {
+		r := Float(CursorHash(serverSeed, clientSeed, nonce, i))
+		j := i + int(r*float64(KenoNumbers-i))
+		numbers[i], numbers[j] = numbers[j], numbers[i]
+	}
+
+	drawn := append([]int(nil), numbers[:KenoDrawSize]...)
+	sort.Ints(drawn)
+	return drawn
+}
@@ -0,0 +1,56 @@
+package fairness
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MinesTiles is the number of tiles on the 5×5 mines board.
+const MinesTiles = 25
+
+// CursorHash extends GameHash for games that need more randomness than one
+// digest provides. Cursor 0 hashes "clientSeed:nonce:0", cursor 1
+// "clientSeed:nonce:1" and so on.
+func CursorHash(serverSeed, clientSeed string, nonce uint64, cursor int) string {
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	fmt.Fprintf(mac, "%s:%d:%d", clientSeed, nonce, cursor)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// MinePositions shuffles the board with a Fisher-Yates shuffle driven by
+// CursorHash and returns the first count tiles, sorted, as mines.
+func MinePositions(serverSeed, clientSeed string, nonce uint64, count int) []int {
+	tiles := make([]int, MinesTiles)
+	for i := range tiles {
+		tiles[i] = i
+	}
+
+	for i := 0; i < count && i < MinesTiles-1; i++ {
+		r := Float(CursorHash(serverSeed, clientSeed, nonce, i))
+		j := i + int(r*float64(MinesTiles-i))
+		tiles[i], tiles[j] = tiles[j], tiles[i]
+	}
+
+	mines := append([]int(nil), tiles[:count]...)
+	sort.Ints(mines)
+	return mines
+}
+
+// MinesMultiplier returns the payout multiplier after revealed safe tiles on a
+// board with the given number of mines, after the house edge and rounded down
+// to four decimals.
+func MinesMultiplier(mines, revealed int, houseEdge float64) float64 {
+	if revealed <= 0 {
+		return 1
+	}
+
+	odds := 1.0
+	for k := 0; k < revealed; k++ {
+		odds *= float64(MinesTiles-k) / float64(MinesTiles-mines-k)
+	}
+	return math.Floor(odds*(1-houseEdge/100)*10000) / 10000
+}
@@ -0,0 +1,43 @@
+package fairness
+
+// PlinkoPath derives the ball path for a board with the given number of rows.
+// Each step is 0 for left and 1 for right, decided by the row's CursorHash.
+// The landing slot is the number of right steps.
+func PlinkoPath(serverSeed, clientSeed string, nonce uint64, rows int) ([]int, int) {
+	path := make([]int, rows)
+	slot := 0
+	for i := 0; i < rows; i++ {
+		if Float(CursorHash(serverSeed, clientSeed, nonce, i)) >= 0.5 {
+			path[i] = 1
+			slot++
+		}
+	}
+	return path, slot
+}
+
+// PlinkoRTP returns the theoretical return to player, as a fraction, of a
+// payout table with one multiplier per slot.
+func PlinkoRTP(multipliers []float64) float64 {
+	rows := len(multipliers) - 1
+	if rows < 1 {
+		return 0
+	}
+
+	var rtp float64
+	chance := 1.0
+	for i := 0; i < rows; i++ {
+		chance /= 2
+	}
+	for slot, multiplier := range multipliers {
+		rtp += binomial(rows, slot) * chance * multiplier
+	}
+	return rtp
+}
+
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
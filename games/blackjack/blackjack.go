@@ -0,0 +1,338 @@
+// Package blackjack implements the rules of single-player blackjack as a
+// state machine. A Game is plain data so it can be stored between API calls;
+// cards come from a DrawFunc supplied by the caller, which owns the shoe.
+package blackjack
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	PhaseInsurance = "insurance"
+	PhasePlayer    = "player"
+	PhaseSettled   = "settled"
+)
+
+const (
+	ActionHit         = "hit"
+	ActionStand       = "stand"
+	ActionDouble      = "double"
+	ActionSplit       = "split"
+	ActionInsurance   = "insurance"
+	ActionNoInsurance = "no_insurance"
+)
+
+const (
+	ResultBlackjack = "blackjack"
+	ResultWin       = "win"
+	ResultPush      = "push"
+	ResultLose      = "lose"
+	ResultBust      = "bust"
+)
+
+// MaxHands is the number of hands a player can hold after splitting.
+const MaxHands = 4
+
+// Actions lists every action in the order clients usually show them.
+var Actions = []string{ActionHit, ActionStand, ActionDouble, ActionSplit, ActionInsurance, ActionNoInsurance}
+
+// ErrActionNotAllowed is wrapped by every error returned for an action that
+// doesn't fit the current state of the game.
+var ErrActionNotAllowed = errors.New("action not allowed")
+
+// Card is an index between 0 and 51: the rank is Card%13 (ace first) and the
+// suit Card/13.
+type Card int
+
+// Rank returns 1 for an ace up to 13 for a king.
+func (c Card) Rank() int {
+	return int(c)%13 + 1
+}
+
+// Value returns the blackjack value of the card, counting an ace as 1.
+func (c Card) Value() int {
+	if rank := c.Rank(); rank < 10 {
+		return rank
+	}
+	return 10
+}
+
+func (c Card) String() string {
+	ranks := []string{"A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"}
+	suits := []string{"S", "H", "D", "C"}
+	return ranks[c.Rank()-1] + suits[int(c)/13%4]
+}
+
+// DrawFunc returns the next card of the shoe.
+type DrawFunc func() Card
+
+type Rules struct {
+	DealerHitsSoft17 bool `json:"dealer_hits_soft_17"`
+}
+
+type Hand struct {
+	Cards   []Card  `json:"cards"`
+	Bet     float64 `json:"bet"`
+	Doubled bool    `json:"doubled"`
+	Split   bool    `json:"split"`
+	Done    bool    `json:"done"`
+	Result  string  `json:"result,omitempty"`
+	Payout  float64 `json:"payout"`
+}
+
+type Game struct {
+	Rules           Rules   `json:"rules"`
+	Hands           []Hand  `json:"hands"`
+	Active          int     `json:"active"`
+	Dealer          []Card  `json:"dealer"`
+	Insurance       float64 `json:"insurance"`
+	InsurancePayout float64 `json:"insurance_payout"`
+	Phase           string  `json:"phase"`
+}
+
+// Total returns the best total of the cards and whether an ace is counted as
+// 11 in it.
+func Total(cards []Card) (int, bool) {
+	total := 0
+	aces := false
+	for _, card := range cards {
+		total += card.Value()
+		if card.Rank() == 1 {
+			aces = true
+		}
+	}
+	if aces && total+10 <= 21 {
+		return total + 10, true
+	}
+	return total, false
+}
+
+// IsBlackjack reports whether cards are a natural: an ace and a ten valued
+// card as the first two cards of an unsplit hand.
+func IsBlackjack(cards []Card) bool {
+	total, _ := Total(cards)
+	return len(cards) == 2 && total == 21
+}
+
+// Deal starts a game with one hand of bet, dealing player, dealer, player,
+// dealer. The game is settled straight away when either side has a natural
+// and no insurance decision is pending.
+func Deal(bet float64, rules Rules, draw DrawFunc) *Game {
+	player := []Card{draw()}
+	dealer := []Card{draw()}
+	player = append(player, draw())
+	dealer = append(dealer, draw())
+
+	g := &Game{
+		Rules:  rules,
+		Hands:  []Hand{{Cards: player, Bet: bet}},
+		Dealer: dealer,
+	}
+
+	if dealer[0].Rank() == 1 {
+		g.Phase = PhaseInsurance
+		return g
+	}
+	g.peek(draw)
+	return g
+}
+
+// Cost validates action against the current state and returns the extra
+// stake it takes: the hand's bet for a double or split and half the original
+// bet for insurance.
+func (g *Game) Cost(action string) (float64, error) {
+	switch action {
+	case ActionInsurance, ActionNoInsurance:
+		if g.Phase != PhaseInsurance {
+			return 0, fmt.Errorf("%w: insurance is only offered against a dealer ace", ErrActionNotAllowed)
+		}
+		if action == ActionInsurance {
+			return g.Hands[0].Bet / 2, nil
+		}
+		return 0, nil
+	case ActionHit, ActionStand, ActionDouble, ActionSplit:
+		if g.Phase != PhasePlayer {
+			return 0, fmt.Errorf("%w: no hand is waiting for an action", ErrActionNotAllowed)
+		}
+	default:
+		return 0, fmt.Errorf("%w: unknown action %q", ErrActionNotAllowed, action)
+	}
+
+	hand := g.Hands[g.Active]
+	switch action {
+	case ActionDouble:
+		if len(hand.Cards) != 2 {
+			return 0, fmt.Errorf("%w: only a two card hand can be doubled", ErrActionNotAllowed)
+		}
+		return hand.Bet, nil
+	case ActionSplit:
+		if len(hand.Cards) != 2 || hand.Cards[0].Rank() != hand.Cards[1].Rank() {
+			return 0, fmt.Errorf("%w: only a pair can be split", ErrActionNotAllowed)
+		}
+		if len(g.Hands) >= MaxHands {
+			return 0, fmt.Errorf("%w: at most %d hands can be played", ErrActionNotAllowed, MaxHands)
+		}
+		return hand.Bet, nil
+	}
+	return 0, nil
+}
+
+// Allowed returns the actions that can be taken in the current state.
+func (g *Game) Allowed() []string {
+	allowed := []string{}
+	for _, action := range Actions {
+		if _, err := g.Cost(action); err == nil {
+			allowed = append(allowed, action)
+		}
+	}
+	return allowed
+}
+
+// Apply moves the game on by one action. The caller is expected to have
+// collected the stake returned by Cost beforehand.
+func (g *Game) Apply(action string, draw DrawFunc) error {
+	if _, err := g.Cost(action); err != nil {
+		return err
+	}
+
+	switch action {
+	case ActionInsurance:
+		g.Insurance = g.Hands[0].Bet / 2
+		g.peek(draw)
+		return nil
+	case ActionNoInsurance:
+		g.peek(draw)
+		return nil
+	}
+
+	hand := &g.Hands[g.Active]
+	switch action {
+	case ActionHit:
+		hand.Cards = append(hand.Cards, draw())
+		if total, _ := Total(hand.Cards); total >= 21 {
+			hand.Done = true
+		}
+	case ActionStand:
+		hand.Done = true
+	case ActionDouble:
+		hand.Bet *= 2
+		hand.Doubled = true
+		hand.Cards = append(hand.Cards, draw())
+		hand.Done = true
+	case ActionSplit:
+		second := Hand{Cards: []Card{hand.Cards[1]}, Bet: hand.Bet, Split: true}
+		hand.Cards = []Card{hand.Cards[0]}
+		hand.Split = true
+
+		g.Hands = append(g.Hands[:g.Active+1], append([]Hand{second}, g.Hands[g.Active+1:]...)...)
+		aces := g.Hands[g.Active].Cards[0].Rank() == 1
+		for _, i := range []int{g.Active, g.Active + 1} {
+			h := &g.Hands[i]
+			h.Cards = append(h.Cards, draw())
+			// Split aces get one card each and stand.
+			if total, _ := Total(h.Cards); aces || total == 21 {
+				h.Done = true
+			}
+		}
+	}
+
+	g.advance(draw)
+	return nil
+}
+
+// Stake returns everything the player has put on the table.
+func (g *Game) Stake() float64 {
+	stake := g.Insurance
+	for _, hand := range g.Hands {
+		stake += hand.Bet
+	}
+	return stake
+}
+
+// Payout returns the total paid back to the player, stakes included. It is
+// only final once the game is settled.
+func (g *Game) Payout() float64 {
+	payout := g.InsurancePayout
+	for _, hand := range g.Hands {
+		payout += hand.Payout
+	}
+	return payout
+}
+
+// peek checks the dealer's hole card once insurance is out of the way and
+// hands the game to the player when nobody has a natural.
+func (g *Game) peek(draw DrawFunc) {
+	if IsBlackjack(g.Dealer) {
+		g.InsurancePayout = g.Insurance * 3
+		g.finish(draw)
+		return
+	}
+	if IsBlackjack(g.Hands[0].Cards) {
+		g.finish(draw)
+		return
+	}
+	g.Phase = PhasePlayer
+	g.Active = 0
+}
+
+func (g *Game) advance(draw DrawFunc) {
+	for g.Active < len(g.Hands) && g.Hands[g.Active].Done {
+		g.Active++
+	}
+	if g.Active == len(g.Hands) {
+		g.finish(draw)
+	}
+}
+
+func (g *Game) natural(hand Hand) bool {
+	return !hand.Split && IsBlackjack(hand.Cards)
+}
+
+// finish plays the dealer's hand when a hand is still live and settles every
+// hand against it.
+func (g *Game) finish(draw DrawFunc) {
+	dealerBlackjack := IsBlackjack(g.Dealer)
+
+	live := false
+	for _, hand := range g.Hands {
+		if total, _ := Total(hand.Cards); total <= 21 && !g.natural(hand) {
+			live = true
+		}
+	}
+	if live && !dealerBlackjack {
+		for {
+			total, soft := Total(g.Dealer)
+			if total > 17 || (total == 17 && !(soft && g.Rules.DealerHitsSoft17)) {
+				break
+			}
+			g.Dealer = append(g.Dealer, draw())
+		}
+	}
+
+	dealerTotal, _ := Total(g.Dealer)
+	for i := range g.Hands {
+		hand := &g.Hands[i]
+		total, _ := Total(hand.Cards)
+		hand.Done = true
+
+		switch {
+		case total > 21:
+			hand.Result, hand.Payout = ResultBust, 0
+		case g.natural(*hand) && dealerBlackjack:
+			hand.Result, hand.Payout = ResultPush, hand.Bet
+		case g.natural(*hand):
+			hand.Result, hand.Payout = ResultBlackjack, hand.Bet*2.5
+		case dealerBlackjack:
+			hand.Result, hand.Payout = ResultLose, 0
+		case dealerTotal > 21 || total > dealerTotal:
+			hand.Result, hand.Payout = ResultWin, hand.Bet*2
+		case total == dealerTotal:
+			hand.Result, hand.Payout = ResultPush, hand.Bet
+		default:
+			hand.Result, hand.Payout = ResultLose, 0
+		}
+	}
+
+	g.Phase = PhaseSettled
+}
@@ -0,0 +1,264 @@
+package blackjack
+
+import (
+	"errors"
+	"testing"
+)
+
+// card returns a spade of rank, 1 for an ace up to 13 for a king.
+func card(rank int) Card {
+	return Card(rank - 1)
+}
+
+// shoe deals the given ranks in order and fails the test if the game asks for
+// more cards than it was given.
+func shoe(t *testing.T, ranks ...int) DrawFunc {
+	t.Helper()
+	return func() Card {
+		if len(ranks) == 0 {
+			t.Fatal("shoe is empty")
+		}
+		next := card(ranks[0])
+		ranks = ranks[1:]
+		return next
+	}
+}
+
+func TestTotal(t *testing.T) {
+	tests := []struct {
+		ranks []int
+		total int
+		soft  bool
+	}{
+		{[]int{10, 13}, 20, false},
+		{[]int{1, 6}, 17, true},
+		{[]int{1, 6, 10}, 17, false},
+		{[]int{1, 1, 9}, 21, true},
+		{[]int{1, 12}, 21, true},
+		{[]int{10, 6, 8}, 24, false},
+	}
+
+	for _, tt := range tests {
+		var cards []Card
+		for _, rank := range tt.ranks {
+			cards = append(cards, card(rank))
+		}
+		total, soft := Total(cards)
+		if total != tt.total || soft != tt.soft {
+			t.Errorf("Total(%v) = %d, %v, want %d, %v", cards, total, soft, tt.total, tt.soft)
+		}
+	}
+}
+
+func TestPlay(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   Rules
+		ranks   []int // player, dealer, player, dealer, then every card drawn later
+		actions []string
+		results []string
+		stake   float64
+		payout  float64
+	}{
+		{
+			name:    "player blackjack",
+			ranks:   []int{1, 9, 13, 7},
+			results: []string{ResultBlackjack},
+			stake:   10,
+			payout:  25,
+		},
+		{
+			name:    "both have blackjack",
+			ranks:   []int{1, 13, 12, 1},
+			results: []string{ResultPush},
+			stake:   10,
+			payout:  10,
+		},
+		{
+			name:    "insurance against a dealer blackjack",
+			ranks:   []int{10, 1, 9, 13},
+			actions: []string{ActionInsurance},
+			results: []string{ResultLose},
+			stake:   15,
+			payout:  15,
+		},
+		{
+			name:    "insurance declined and dealer has no blackjack",
+			ranks:   []int{10, 1, 9, 7},
+			actions: []string{ActionNoInsurance, ActionStand},
+			results: []string{ResultWin},
+			stake:   10,
+			payout:  20,
+		},
+		{
+			name:    "hit and bust",
+			ranks:   []int{10, 9, 6, 8, 13},
+			actions: []string{ActionHit},
+			results: []string{ResultBust},
+			stake:   10,
+			payout:  0,
+		},
+		{
+			name:    "dealer draws to 17",
+			ranks:   []int{10, 10, 9, 6, 2},
+			actions: []string{ActionStand},
+			results: []string{ResultWin},
+			stake:   10,
+			payout:  20,
+		},
+		{
+			name:    "dealer busts",
+			ranks:   []int{10, 10, 2, 6, 9},
+			actions: []string{ActionStand},
+			results: []string{ResultWin},
+			stake:   10,
+			payout:  20,
+		},
+		{
+			name:    "push",
+			ranks:   []int{10, 10, 8, 8},
+			actions: []string{ActionStand},
+			results: []string{ResultPush},
+			stake:   10,
+			payout:  10,
+		},
+		{
+			name:    "double down",
+			ranks:   []int{5, 10, 6, 7, 10},
+			actions: []string{ActionDouble},
+			results: []string{ResultWin},
+			stake:   20,
+			payout:  40,
+		},
+		{
+			name:    "split eights",
+			ranks:   []int{8, 10, 8, 7, 3, 10},
+			actions: []string{ActionSplit, ActionStand, ActionStand},
+			results: []string{ResultLose, ResultWin},
+			stake:   20,
+			payout:  20,
+		},
+		{
+			name:    "split aces take one card each",
+			ranks:   []int{1, 10, 1, 7, 13, 9},
+			actions: []string{ActionSplit},
+			results: []string{ResultWin, ResultWin},
+			stake:   20,
+			payout:  40,
+		},
+		{
+			name:    "dealer stands on soft 17",
+			ranks:   []int{10, 6, 7, 1},
+			actions: []string{ActionStand},
+			results: []string{ResultPush},
+			stake:   10,
+			payout:  10,
+		},
+		{
+			name:    "dealer hits soft 17",
+			rules:   Rules{DealerHitsSoft17: true},
+			ranks:   []int{10, 6, 7, 1, 5, 10},
+			actions: []string{ActionStand},
+			results: []string{ResultWin},
+			stake:   10,
+			payout:  20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draw := shoe(t, tt.ranks...)
+			game := Deal(10, tt.rules, draw)
+			for _, action := range tt.actions {
+				if err := game.Apply(action, draw); err != nil {
+					t.Fatalf("Apply(%s) failed: %v", action, err)
+				}
+			}
+
+			if game.Phase != PhaseSettled {
+				t.Fatalf("game is in phase %s, want settled", game.Phase)
+			}
+			if len(game.Hands) != len(tt.results) {
+				t.Fatalf("game has %d hands, want %d", len(game.Hands), len(tt.results))
+			}
+			for i, hand := range game.Hands {
+				if hand.Result != tt.results[i] {
+					t.Errorf("hand %d result is %s, want %s", i, hand.Result, tt.results[i])
+				}
+			}
+			if got := game.Stake(); got != tt.stake {
+				t.Errorf("Stake() = %v, want %v", got, tt.stake)
+			}
+			if got := game.Payout(); got != tt.payout {
+				t.Errorf("Payout() = %v, want %v", got, tt.payout)
+			}
+		})
+	}
+}
+
+func TestCost(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranks  []int
+		action string
+		cost   float64
+		ok     bool
+	}{
+		{"double", []int{5, 10, 6, 7}, ActionDouble, 10, true},
+		{"split a pair", []int{8, 10, 8, 7}, ActionSplit, 10, true},
+		{"split ten values of different rank", []int{10, 10, 13, 7}, ActionSplit, 0, false},
+		{"insurance without a dealer ace", []int{5, 10, 6, 7}, ActionInsurance, 0, false},
+		{"insurance against a dealer ace", []int{5, 1, 6, 7}, ActionInsurance, 5, true},
+		{"hit while insurance is pending", []int{5, 1, 6, 7}, ActionHit, 0, false},
+		{"unknown action", []int{5, 10, 6, 7}, "surrender", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := Deal(10, Rules{}, shoe(t, tt.ranks...))
+			cost, err := game.Cost(tt.action)
+			if !tt.ok {
+				if !errors.Is(err, ErrActionNotAllowed) {
+					t.Errorf("Cost(%s) = %v, want ErrActionNotAllowed", tt.action, err)
+				}
+				return
+			}
+			if err != nil || cost != tt.cost {
+				t.Errorf("Cost(%s) = %v, %v, want %v", tt.action, cost, err, tt.cost)
+			}
+		})
+	}
+}
+
+func TestNoDoubleAfterHit(t *testing.T) {
+	draw := shoe(t, 2, 10, 3, 7, 4)
+	game := Deal(10, Rules{}, draw)
+	if err := game.Apply(ActionHit, draw); err != nil {
+		t.Fatalf("Apply(hit) failed: %v", err)
+	}
+
+	if err := game.Apply(ActionDouble, draw); !errors.Is(err, ErrActionNotAllowed) {
+		t.Errorf("Apply(double) on three cards = %v, want ErrActionNotAllowed", err)
+	}
+	allowed := game.Allowed()
+	if len(allowed) != 2 || allowed[0] != ActionHit || allowed[1] != ActionStand {
+		t.Errorf("Allowed() = %v, want [hit stand]", allowed)
+	}
+}
+
+func TestSplitLimit(t *testing.T) {
+	draw := shoe(t, 8, 10, 8, 7, 8, 8, 8, 8, 8, 8)
+	game := Deal(10, Rules{}, draw)
+	for i := 0; i < MaxHands-1; i++ {
+		if err := game.Apply(ActionSplit, draw); err != nil {
+			t.Fatalf("split %d failed: %v", i+1, err)
+		}
+	}
+
+	if len(game.Hands) != MaxHands {
+		t.Fatalf("game has %d hands, want %d", len(game.Hands), MaxHands)
+	}
+	if _, err := game.Cost(ActionSplit); !errors.Is(err, ErrActionNotAllowed) {
+		t.Errorf("Cost(split) with %d hands = %v, want ErrActionNotAllowed", MaxHands, err)
+	}
+}
@@ -0,0 +1,55 @@
+// Package crash holds the rules of a crash bet that do not depend on storage:
+// how far the multiplier has climbed, where the server cashes a bet out and
+// what a stopped bet pays. The round scheduler and the simulator both play by
+// these rules.
+package crash
+
+import (
+	"casino_api_go/curve"
+	"math"
+)
+
+// Multiplier is the multiplier elapsed seconds into a round, capped at the
+// max multiplier.
+func Multiplier(growth curve.Curve, elapsed, maxMultiplier float64) float64 {
+	multiplier := growth.Multiplier(elapsed)
+	if multiplier > maxMultiplier {
+		multiplier = maxMultiplier
+	}
+	return multiplier
+}
+
+// MaxWinMultiplier returns the multiplier at which stake reaches the max win
+// per bet, given what the bet already won, or nil when no cap is configured.
+// It is rounded down to two decimals and never below 1.
+func MaxWinMultiplier(maxWinPerBet, won, stake float64) *float64 {
+	if maxWinPerBet <= 0 || stake <= 0 {
+		return nil
+	}
+
+	multiplier := math.Floor((maxWinPerBet-won)/stake*100) / 100
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	return &multiplier
+}
+
+// CashoutTarget is the multiplier the server cashes a bet out at: the auto
+// cashout or the max win limit, whichever comes first.
+func CashoutTarget(autoCashout, limit *float64) *float64 {
+	target := autoCashout
+	if limit != nil && (target == nil || *limit < *target) {
+		target = limit
+	}
+	return target
+}
+
+// Settle returns the multiplier a bet stopped at multiplier is settled at and
+// whether it won. A target that was already reached pays exactly the target,
+// however late the stop came in.
+func Settle(multiplier, crashPoint float64, target *float64) (float64, bool) {
+	if target != nil && *target <= multiplier {
+		multiplier = *target
+	}
+	return multiplier, multiplier < crashPoint
+}
@@ -0,0 +1,90 @@
+package crash
+
+import (
+	"testing"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestSettle(t *testing.T) {
+	tests := []struct {
+		name           string
+		multiplier     float64
+		crashPoint     float64
+		target         *float64
+		wantMultiplier float64
+		wantWon        bool
+	}{
+		{"manual stop before the crash", 1.8, 2.5, nil, 1.8, true},
+		{"stopped at the crash point", 2.5, 2.5, nil, 2.5, false},
+		{"instant crash", 1, 1, nil, 1, false},
+		{"late stop pays the target", 3.2, 5, float(2), 2, true},
+		{"target reached at the crash", 2.5, 2.5, float(2), 2, true},
+		{"target not reached yet", 1.5, 5, float(2), 1.5, true},
+		{"target at the crash point loses", 3, 2, float(2), 2, false},
+		{"crash before the target", 1.4, 1.4, float(2), 1.4, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			multiplier, won := Settle(tt.multiplier, tt.crashPoint, tt.target)
+			if multiplier != tt.wantMultiplier || won != tt.wantWon {
+				t.Errorf("Settle() = %v, %v, want %v, %v", multiplier, won, tt.wantMultiplier, tt.wantWon)
+			}
+		})
+	}
+}
+
+func TestCashoutTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoCashout *float64
+		limit       *float64
+		want        *float64
+	}{
+		{"neither", nil, nil, nil},
+		{"auto cashout only", float(2), nil, float(2)},
+		{"limit only", nil, float(10), float(10)},
+		{"auto cashout first", float(2), float(10), float(2)},
+		{"limit first", float(20), float(10), float(10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CashoutTarget(tt.autoCashout, tt.limit)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("CashoutTarget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxWinMultiplier(t *testing.T) {
+	tests := []struct {
+		name                     string
+		maxWinPerBet, won, stake float64
+		want                     *float64
+	}{
+		{"no cap", 0, 0, 10, nil},
+		{"no stake", 1000, 0, 0, nil},
+		{"fresh bet", 1000, 0, 10, float(100)},
+		{"rounded down", 1000, 0, 30, float(33.33)},
+		{"after a partial cashout", 1000, 400, 10, float(60)},
+		{"never below 1", 1000, 995, 10, float(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MaxWinMultiplier(tt.maxWinPerBet, tt.won, tt.stake)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("MaxWinMultiplier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package crash
+
+import (
+	"casino_api_go/curve"
+	"casino_api_go/fairness"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+const (
+	StrategyFixed  = "fixed"
+	StrategyRandom = "random"
+	StrategyAuto   = "auto"
+
+	simulationClientSeed = "casino_api_go:simulation"
+)
+
+// Settings is what a simulated round is played with, taken from GameSettings.
+type Settings struct {
+	Params       fairness.Params
+	Curve        curve.Curve
+	MaxWinPerBet float64
+}
+
+// Strategy is how a simulated player bets every round. A fixed player stops
+// by hand once the multiplier shows Target and is Delay seconds late doing
+// it; a random player does the same with a target drawn between Min and Max
+// each round; an auto player leaves Target to the server's auto cashout.
+type Strategy struct {
+	Kind   string  `json:"kind"`
+	Bet    float64 `json:"bet"`
+	Target float64 `json:"target,omitempty"`
+	Min    float64 `json:"min,omitempty"`
+	Max    float64 `json:"max,omitempty"`
+	Delay  float64 `json:"delay,omitempty"`
+}
+
+// DefaultStrategies compares auto cashout, a slightly late manual cashout at
+// the same target and a player with no fixed plan. The bet is left to the
+// caller.
+var DefaultStrategies = []Strategy{
+	{Kind: StrategyAuto, Target: 2},
+	{Kind: StrategyFixed, Target: 2, Delay: 0.2},
+	{Kind: StrategyRandom, Min: 1.1, Max: 10},
+}
+
+func (s Strategy) Validate() error {
+	if s.Bet <= 0 {
+		return errors.New("bet must be positive")
+	}
+	if s.Delay < 0 || s.Delay > 10 {
+		return errors.New("delay must be between 0 and 10 seconds")
+	}
+
+	switch s.Kind {
+	case StrategyFixed, StrategyAuto:
+		if s.Target <= 1 {
+			return fmt.Errorf("%s strategy needs a target above 1", s.Kind)
+		}
+	case StrategyRandom:
+		if s.Min <= 1 || s.Max <= s.Min {
+			return errors.New("random strategy needs 1 < min < max")
+		}
+	default:
+		return fmt.Errorf("unknown strategy %q", s.Kind)
+	}
+	return nil
+}
+
+func (s Strategy) String() string {
+	var name string
+	switch s.Kind {
+	case StrategyRandom:
+		name = fmt.Sprintf("random %.2fx-%.2fx", s.Min, s.Max)
+	default:
+		name = fmt.Sprintf("%s %.2fx", s.Kind, s.Target)
+	}
+	if s.Delay > 0 && s.Kind != StrategyAuto {
+		name += fmt.Sprintf(" +%.2fs", s.Delay)
+	}
+	return name
+}
+
+// Bucket counts the values in [From, To). To is nil for the last bucket.
+type Bucket struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to"`
+	Count int      `json:"count"`
+	Share float64  `json:"share"` // percentage of all values
+}
+
+// bucketEdges split multipliers into instant crashes or lost bets, then
+// ranges that grow with the multiplier.
+var bucketEdges = []float64{1.01, 1.5, 2, 3, 5, 10, 50, 100, 1000}
+
+type histogram []Bucket
+
+func newHistogram() histogram {
+	buckets := make(histogram, len(bucketEdges)+1)
+	for i := range buckets {
+		if i > 0 {
+			buckets[i].From = bucketEdges[i-1]
+		}
+		if i < len(bucketEdges) {
+			edge := bucketEdges[i]
+			buckets[i].To = &edge
+		}
+	}
+	return buckets
+}
+
+func (h histogram) add(value float64) {
+	i := 0
+	for i < len(bucketEdges) && value >= bucketEdges[i] {
+		i++
+	}
+	h[i].Count++
+}
+
+func (h histogram) finish(total int) []Bucket {
+	if total > 0 {
+		for i := range h {
+			h[i].Share = float64(h[i].Count) / float64(total) * 100
+		}
+	}
+	return h
+}
+
+// StrategyResult is what one strategy did over the simulation. RTP and
+// HitRate are percentages. Variance is the variance of the payout multiplier
+// of a single bet. MaxDrawdown is the largest fall of the house's running
+// profit against this player from an earlier high.
+type StrategyResult struct {
+	Strategy       Strategy `json:"strategy"`
+	Name           string   `json:"name"`
+	Bets           int      `json:"bets"`
+	TotalBet       float64  `json:"total_bet"`
+	TotalPayout    float64  `json:"total_payout"`
+	RTP            float64  `json:"rtp"`
+	TheoreticalRTP *float64 `json:"theoretical_rtp"`
+	HitRate        float64  `json:"hit_rate"`
+	Variance       float64  `json:"variance"`
+	StdDev         float64  `json:"std_dev"`
+	MaxDrawdown    float64  `json:"max_drawdown"`
+	Payouts        []Bucket `json:"payouts"`
+}
+
+type Report struct {
+	Rounds           int              `json:"rounds"`
+	Seed             string           `json:"seed"`
+	InstantCrashRate float64          `json:"instant_crash_rate"`
+	CrashPoints      []Bucket         `json:"crash_points"`
+	Strategies       []StrategyResult `json:"strategies"`
+}
+
+type player struct {
+	strategy Strategy
+	limit    *float64
+	result   StrategyResult
+	payouts  histogram
+	mean, m2 float64
+	profit   float64
+	peak     float64
+	wins     int
+}
+
+// target is the multiplier the player means to stop at this round.
+func (p *player) target(source *rand.Rand) float64 {
+	if p.strategy.Kind == StrategyRandom {
+		return p.strategy.Min + source.Float64()*(p.strategy.Max-p.strategy.Min)
+	}
+	return p.strategy.Target
+}
+
+// play settles one bet the way the round scheduler would: the server stops
+// the bet at its auto cashout or max win limit, and a manual stop lands where
+// the curve is when the player's click arrives.
+func (p *player) play(settings Settings, crashPoint, target float64) {
+	stopped := crashPoint
+	serverTarget := p.limit
+	if p.strategy.Kind == StrategyAuto {
+		serverTarget = CashoutTarget(&target, p.limit)
+	} else {
+		elapsed := settings.Curve.TimeTo(target) + p.strategy.Delay
+		stopped = Multiplier(settings.Curve, elapsed, settings.Params.MaxMultiplier)
+	}
+
+	multiplier, won := Settle(stopped, crashPoint, serverTarget)
+	payout := 0.0
+	if won {
+		payout = p.strategy.Bet * multiplier
+		p.wins++
+	}
+
+	r := &p.result
+	r.Bets++
+	r.TotalBet += p.strategy.Bet
+	r.TotalPayout += payout
+
+	value := payout / p.strategy.Bet
+	p.payouts.add(value)
+	delta := value - p.mean
+	p.mean += delta / float64(r.Bets)
+	p.m2 += delta * (value - p.mean)
+
+	p.profit += p.strategy.Bet - payout
+	if p.profit > p.peak {
+		p.peak = p.profit
+	}
+	if drawdown := p.peak - p.profit; drawdown > r.MaxDrawdown {
+		r.MaxDrawdown = drawdown
+	}
+}
+
+func (p *player) finish(settings Settings) StrategyResult {
+	r := p.result
+	r.Name = p.strategy.String()
+	if r.Bets > 0 {
+		r.RTP = r.TotalPayout / r.TotalBet * 100
+		r.HitRate = float64(p.wins) / float64(r.Bets) * 100
+		r.Variance = p.m2 / float64(r.Bets)
+		r.StdDev = math.Sqrt(r.Variance)
+	}
+
+	// The closed form only holds for a stop exactly at the target that no
+	// max win limit cuts short.
+	exact := p.strategy.Kind == StrategyAuto || p.strategy.Delay == 0
+	if p.strategy.Kind != StrategyRandom && exact && (p.limit == nil || *p.limit >= p.strategy.Target) {
+		rtp := fairness.RTP(settings.Params, p.strategy.Target) * 100
+		r.TheoreticalRTP = &rtp
+	}
+
+	r.Payouts = p.payouts.finish(r.Bets)
+	return r
+}
+
+// Simulate plays rounds crash rounds with every strategy betting on each.
+// Crash points come from fairness.CrashPoint over HMAC game hashes of seed, so
+// a run can be repeated with the same seed.
+func Simulate(settings Settings, strategies []Strategy, rounds int, seed string) Report {
+	digest := sha256.Sum256([]byte(seed))
+	source := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(digest[:8]))))
+
+	players := make([]*player, len(strategies))
+	for i, strategy := range strategies {
+		players[i] = &player{
+			strategy: strategy,
+			limit:    MaxWinMultiplier(settings.MaxWinPerBet, 0, strategy.Bet),
+			result:   StrategyResult{Strategy: strategy},
+			payouts:  newHistogram(),
+		}
+	}
+
+	report := Report{Rounds: rounds, Seed: seed}
+	crashPoints := newHistogram()
+	instant := 0
+	for i := 0; i < rounds; i++ {
+		crashPoint := fairness.CrashPoint(fairness.GameHash(seed, simulationClientSeed, uint64(i)), settings.Params)
+		crashPoints.add(crashPoint)
+		if crashPoint <= 1 {
+			instant++
+		}
+
+		for _, p := range players {
+			p.play(settings, crashPoint, p.target(source))
+		}
+	}
+
+	if rounds > 0 {
+		report.InstantCrashRate = float64(instant) / float64(rounds) * 100
+	}
+	report.CrashPoints = crashPoints.finish(rounds)
+	for _, p := range players {
+		report.Strategies = append(report.Strategies, p.finish(settings))
+	}
+	return report
+}
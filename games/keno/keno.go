@@ -0,0 +1,117 @@
+// Package keno holds the keno rules: which picks are valid, how hits are
+// counted and what a paytable returns.
+package keno
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	Numbers  = 80
+	DrawSize = 20
+	MaxPicks = 10
+)
+
+// Paytable maps the number of picks to the multiplier of the bet paid for
+// each number of hits, from zero hits up to all of them.
+type Paytable map[int][]float64
+
+// DefaultPaytable returns between 92% and 97% for every number of picks.
+var DefaultPaytable = Paytable{
+	1:  {0, 3.8},
+	2:  {0, 1, 9.5},
+	3:  {0, 0, 2.5, 44},
+	4:  {0, 0, 2, 6, 89},
+	5:  {0, 0, 1, 3.5, 16, 320},
+	6:  {0, 0, 0.5, 2, 5.5, 68, 1370},
+	7:  {0, 0, 0.5, 1, 3.5, 17, 220, 4470},
+	8:  {0, 0, 0, 1, 2.5, 11, 63, 810, 8140},
+	9:  {0, 0, 0, 1, 1.5, 5, 25, 210, 2100, 16770},
+	10: {0, 0, 0, 1, 1.5, 3, 11, 52, 520, 3730, 18650},
+}
+
+// ValidatePicks checks that picks holds between 1 and MaxPicks distinct
+// numbers from 1 to Numbers.
+func ValidatePicks(picks []int) error {
+	if len(picks) < 1 || len(picks) > MaxPicks {
+		return fmt.Errorf("pick between 1 and %d numbers", MaxPicks)
+	}
+	seen := make(map[int]bool)
+	for _, n := range picks {
+		if n < 1 || n > Numbers {
+			return fmt.Errorf("numbers must be between 1 and %d", Numbers)
+		}
+		if seen[n] {
+			return errors.New("numbers must not repeat")
+		}
+		seen[n] = true
+	}
+	return nil
+}
+
+// Hits counts how many picks are among the drawn numbers.
+func Hits(picks, drawn []int) int {
+	drawnSet := make(map[int]bool, len(drawn))
+	for _, n := range drawn {
+		drawnSet[n] = true
+	}
+	hits := 0
+	for _, n := range picks {
+		if drawnSet[n] {
+			hits++
+		}
+	}
+	return hits
+}
+
+// Probability returns the chance of exactly hits hits with picks picks.
+func Probability(picks, hits int) float64 {
+	if hits < 0 || hits > picks {
+		return 0
+	}
+	return choose(DrawSize, hits) * choose(Numbers-DrawSize, picks-hits) / choose(Numbers, picks)
+}
+
+// Validate checks that the table has a row for every number of picks with one
+// non-negative multiplier per possible number of hits.
+func (p Paytable) Validate() error {
+	for picks := 1; picks <= MaxPicks; picks++ {
+		row, ok := p[picks]
+		if !ok {
+			return fmt.Errorf("missing payouts for %d picks", picks)
+		}
+		if len(row) != picks+1 {
+			return fmt.Errorf("payouts for %d picks need %d multipliers", picks, picks+1)
+		}
+		for _, multiplier := range row {
+			if multiplier < 0 {
+				return fmt.Errorf("payouts for %d picks can't be negative", picks)
+			}
+		}
+	}
+	if len(p) != MaxPicks {
+		return fmt.Errorf("payouts are only allowed for 1 to %d picks", MaxPicks)
+	}
+	return nil
+}
+
+// RTP returns the expected return of a one unit ticket with picks picks.
+func (p Paytable) RTP(picks int) float64 {
+	var rtp float64
+	for hits, multiplier := range p[picks] {
+		rtp += Probability(picks, hits) * multiplier
+	}
+	return rtp
+}
+
+func choose(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1.0
+	for i := 0; i < k; i++ {
+		result = result * float64(n-i) / float64(i+1)
+	}
+	return result
+}
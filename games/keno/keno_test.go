@@ -0,0 +1,117 @@
+package keno
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidatePicks(t *testing.T) {
+	tests := []struct {
+		name  string
+		picks []int
+		valid bool
+	}{
+		{"one pick", []int{1}, true},
+		{"max picks", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 80}, true},
+		{"no picks", nil, false},
+		{"too many picks", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, false},
+		{"zero", []int{0, 5}, false},
+		{"above the board", []int{81}, false},
+		{"repeated number", []int{7, 7}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePicks(tt.picks); (err == nil) != tt.valid {
+				t.Errorf("ValidatePicks(%v) = %v, want valid %v", tt.picks, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestHits(t *testing.T) {
+	drawn := []int{1, 5, 9, 13, 17, 21, 25, 29, 33, 37, 41, 45, 49, 53, 57, 61, 65, 69, 73, 77}
+
+	tests := []struct {
+		picks []int
+		want  int
+	}{
+		{[]int{2}, 0},
+		{[]int{1}, 1},
+		{[]int{1, 2, 5, 6}, 2},
+		{[]int{1, 5, 9, 13, 17, 21, 25, 29, 33, 37}, 10},
+	}
+
+	for _, tt := range tests {
+		if got := Hits(tt.picks, drawn); got != tt.want {
+			t.Errorf("Hits(%v) = %d, want %d", tt.picks, got, tt.want)
+		}
+	}
+}
+
+func TestProbability(t *testing.T) {
+	if got, want := Probability(1, 1), 0.25; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Probability(1, 1) = %v, want %v", got, want)
+	}
+	if got := Probability(3, 4); got != 0 {
+		t.Errorf("Probability(3, 4) = %v, want 0", got)
+	}
+
+	for picks := 1; picks <= MaxPicks; picks++ {
+		var total float64
+		for hits := 0; hits <= picks; hits++ {
+			total += Probability(picks, hits)
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("probabilities for %d picks add up to %v", picks, total)
+		}
+	}
+}
+
+func TestDefaultPaytable(t *testing.T) {
+	if err := DefaultPaytable.Validate(); err != nil {
+		t.Fatalf("DefaultPaytable is invalid: %v", err)
+	}
+
+	for picks := 1; picks <= MaxPicks; picks++ {
+		if rtp := DefaultPaytable.RTP(picks); rtp < 0.92 || rtp > 0.97 {
+			t.Errorf("RTP with %d picks is %v, want between 0.92 and 0.97", picks, rtp)
+		}
+	}
+}
+
+func TestPaytableValidate(t *testing.T) {
+	missing := Paytable{}
+	for picks, row := range DefaultPaytable {
+		if picks != 4 {
+			missing[picks] = row
+		}
+	}
+
+	short := Paytable{}
+	negative := Paytable{}
+	extra := Paytable{11: make([]float64, 12)}
+	for picks, row := range DefaultPaytable {
+		short[picks], negative[picks], extra[picks] = row, row, row
+	}
+	short[3] = []float64{0, 0, 2.5}
+	negative[2] = []float64{0, -1, 9.5}
+
+	tests := []struct {
+		name     string
+		paytable Paytable
+	}{
+		{"missing row", missing},
+		{"short row", short},
+		{"negative multiplier", negative},
+		{"too many picks", extra},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.paytable.Validate(); err == nil {
+				t.Error("Validate() accepted an invalid paytable")
+			}
+		})
+	}
+}
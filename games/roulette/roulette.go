@@ -0,0 +1,191 @@
+// Package roulette implements single-zero (European) roulette: which numbers
+// a bet covers, whether it wins and what it pays. It has no knowledge of
+// wallets or storage.
+package roulette
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Pockets is the number of pockets on a single-zero wheel.
+const Pockets = 37
+
+const (
+	Straight = "straight"
+	Split    = "split"
+	Street   = "street"
+	Corner   = "corner"
+	Dozen    = "dozen"
+	Column   = "column"
+	Red      = "red"
+	Black    = "black"
+	Odd      = "odd"
+	Even     = "even"
+)
+
+var redNumbers = map[int]bool{
+	1: true, 3: true, 5: true, 7: true, 9: true, 12: true, 14: true, 16: true, 18: true,
+	19: true, 21: true, 23: true, 25: true, 27: true, 30: true, 32: true, 34: true, 36: true,
+}
+
+// Bet is one line of a bet slip. Numbers holds the chosen numbers for inside
+// bets and the dozen or column (1-3) for those bets; it is empty for the even
+// money bets.
+type Bet struct {
+	Type    string
+	Numbers []int
+	Amount  float64
+}
+
+// Pocket maps a uniform value in [0, 1) to a pocket between 0 and 36.
+func Pocket(r float64) int {
+	pocket := int(r * Pockets)
+	if pocket >= Pockets {
+		pocket = Pockets - 1
+	}
+	return pocket
+}
+
+// Color returns "green", "red" or "black" for a pocket.
+func Color(pocket int) string {
+	switch {
+	case pocket == 0:
+		return "green"
+	case redNumbers[pocket]:
+		return "red"
+	default:
+		return "black"
+	}
+}
+
+// Covered validates a bet and returns the numbers it covers, sorted.
+func Covered(betType string, numbers []int) ([]int, error) {
+	sorted := append([]int(nil), numbers...)
+	sort.Ints(sorted)
+
+	switch betType {
+	case Straight:
+		if len(sorted) != 1 || !onTable(sorted[0]) {
+			return nil, errors.New("straight bet needs one number between 0 and 36")
+		}
+		return sorted, nil
+	case Split:
+		if len(sorted) != 2 || !onTable(sorted[0]) || !onTable(sorted[1]) || !adjacent(sorted[0], sorted[1]) {
+			return nil, errors.New("split bet needs two adjacent numbers")
+		}
+		return sorted, nil
+	case Street:
+		if len(sorted) != 3 || !isStreet(sorted) {
+			return nil, errors.New("street bet needs three numbers of one row, or 0 with 1-2 or 2-3")
+		}
+		return sorted, nil
+	case Corner:
+		if len(sorted) != 4 || !isCorner(sorted) {
+			return nil, errors.New("corner bet needs four numbers forming a square, or 0-1-2-3")
+		}
+		return sorted, nil
+	case Dozen:
+		if len(sorted) != 1 || sorted[0] < 1 || sorted[0] > 3 {
+			return nil, errors.New("dozen bet needs a dozen between 1 and 3")
+		}
+		return matching(func(n int) bool { return (n-1)/12 == sorted[0]-1 }), nil
+	case Column:
+		if len(sorted) != 1 || sorted[0] < 1 || sorted[0] > 3 {
+			return nil, errors.New("column bet needs a column between 1 and 3")
+		}
+		return matching(func(n int) bool { return (n-1)%3 == sorted[0]-1 }), nil
+	case Red, Black:
+		color := betType
+		return matching(func(n int) bool { return Color(n) == color }), nil
+	case Odd:
+		return matching(func(n int) bool { return n%2 == 1 }), nil
+	case Even:
+		return matching(func(n int) bool { return n%2 == 0 }), nil
+	default:
+		return nil, fmt.Errorf("unknown bet type %q", betType)
+	}
+}
+
+// Payout returns the total return of a winning bet on covered numbers,
+// including the stake: straight pays 35:1, red pays 1:1 and so on.
+func Payout(amount float64, covered []int) float64 {
+	if len(covered) == 0 {
+		return 0
+	}
+	return amount * 36 / float64(len(covered))
+}
+
+// Wins reports whether pocket is one of the covered numbers.
+func Wins(covered []int, pocket int) bool {
+	for _, n := range covered {
+		if n == pocket {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxPayout returns the largest total return a slip can produce on any
+// pocket.
+func MaxPayout(bets []Bet) (float64, error) {
+	var returns [Pockets]float64
+	for _, bet := range bets {
+		covered, err := Covered(bet.Type, bet.Numbers)
+		if err != nil {
+			return 0, err
+		}
+		payout := Payout(bet.Amount, covered)
+		for _, n := range covered {
+			returns[n] += payout
+		}
+	}
+
+	var max float64
+	for _, value := range returns {
+		if value > max {
+			max = value
+		}
+	}
+	return max, nil
+}
+
+func onTable(n int) bool {
+	return n >= 0 && n <= 36
+}
+
+func matching(match func(int) bool) []int {
+	var numbers []int
+	for n := 1; n <= 36; n++ {
+		if match(n) {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+// adjacent reports whether a < b touch on the layout of three columns.
+func adjacent(a, b int) bool {
+	if a == 0 {
+		return b >= 1 && b <= 3
+	}
+	if b-a == 3 {
+		return true
+	}
+	return b-a == 1 && (a-1)/3 == (b-1)/3
+}
+
+func isStreet(n []int) bool {
+	if n[0] == 0 {
+		return (n[1] == 1 && n[2] == 2) || (n[1] == 2 && n[2] == 3)
+	}
+	return n[0]%3 == 1 && n[1] == n[0]+1 && n[2] == n[0]+2 && n[2] <= 36
+}
+
+func isCorner(n []int) bool {
+	if n[0] == 0 {
+		return n[1] == 1 && n[2] == 2 && n[3] == 3
+	}
+	return n[0]%3 != 0 && n[1] == n[0]+1 && n[2] == n[0]+3 && n[3] == n[0]+4 && n[3] <= 36
+}
@@ -0,0 +1,158 @@
+package roulette
+
+import (
+	"testing"
+)
+
+func TestCovered(t *testing.T) {
+	tests := []struct {
+		name    string
+		betType string
+		numbers []int
+		want    []int
+	}{
+		{"straight", Straight, []int{17}, []int{17}},
+		{"straight on zero", Straight, []int{0}, []int{0}},
+		{"split across", Split, []int{8, 7}, []int{7, 8}},
+		{"split down", Split, []int{7, 10}, []int{7, 10}},
+		{"split with zero", Split, []int{0, 2}, []int{0, 2}},
+		{"street", Street, []int{13, 14, 15}, []int{13, 14, 15}},
+		{"street with zero", Street, []int{0, 2, 3}, []int{0, 2, 3}},
+		{"corner", Corner, []int{17, 18, 20, 21}, []int{17, 18, 20, 21}},
+		{"corner with zero", Corner, []int{0, 1, 2, 3}, []int{0, 1, 2, 3}},
+		{"second dozen", Dozen, []int{2}, []int{13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24}},
+		{"first column", Column, []int{1}, []int{1, 4, 7, 10, 13, 16, 19, 22, 25, 28, 31, 34}},
+		{"red", Red, nil, []int{1, 3, 5, 7, 9, 12, 14, 16, 18, 19, 21, 23, 25, 27, 30, 32, 34, 36}},
+		{"black", Black, nil, []int{2, 4, 6, 8, 10, 11, 13, 15, 17, 20, 22, 24, 26, 28, 29, 31, 33, 35}},
+		{"odd", Odd, nil, []int{1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25, 27, 29, 31, 33, 35}},
+		{"even", Even, nil, []int{2, 4, 6, 8, 10, 12, 14, 16, 18, 20, 22, 24, 26, 28, 30, 32, 34, 36}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Covered(tt.betType, tt.numbers)
+			if err != nil {
+				t.Fatalf("Covered() failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Covered() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Covered() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCoveredRejectsInvalidBets(t *testing.T) {
+	tests := []struct {
+		name    string
+		betType string
+		numbers []int
+	}{
+		{"straight off the table", Straight, []int{37}},
+		{"straight on two numbers", Straight, []int{1, 2}},
+		{"split across rows", Split, []int{3, 4}},
+		{"split far apart", Split, []int{1, 9}},
+		{"split zero with four", Split, []int{0, 4}},
+		{"street across rows", Street, []int{2, 3, 4}},
+		{"street zero with one and three", Street, []int{0, 1, 3}},
+		{"corner across the edge", Corner, []int{3, 4, 6, 7}},
+		{"corner off the table", Corner, []int{35, 36, 38, 39}},
+		{"dozen out of range", Dozen, []int{4}},
+		{"column out of range", Column, []int{0}},
+		{"unknown bet type", "basket", []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if covered, err := Covered(tt.betType, tt.numbers); err == nil {
+				t.Errorf("Covered() accepted the bet, covering %v", covered)
+			}
+		})
+	}
+}
+
+func TestPayout(t *testing.T) {
+	tests := []struct {
+		betType string
+		numbers []int
+		want    float64
+	}{
+		{Straight, []int{17}, 360},
+		{Split, []int{17, 18}, 180},
+		{Street, []int{16, 17, 18}, 120},
+		{Corner, []int{17, 18, 20, 21}, 90},
+		{Dozen, []int{3}, 30},
+		{Column, []int{2}, 30},
+		{Red, nil, 20},
+		{Black, nil, 20},
+		{Odd, nil, 20},
+		{Even, nil, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.betType, func(t *testing.T) {
+			covered, err := Covered(tt.betType, tt.numbers)
+			if err != nil {
+				t.Fatalf("Covered() failed: %v", err)
+			}
+			if got := Payout(10, covered); got != tt.want {
+				t.Errorf("Payout(10) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZeroLosesEvenMoneyBets(t *testing.T) {
+	for _, betType := range []string{Red, Black, Odd, Even} {
+		covered, err := Covered(betType, nil)
+		if err != nil {
+			t.Fatalf("Covered(%s) failed: %v", betType, err)
+		}
+		if Wins(covered, 0) {
+			t.Errorf("%s bet wins on zero", betType)
+		}
+	}
+}
+
+func TestPocket(t *testing.T) {
+	tests := []struct {
+		r    float64
+		want int
+	}{
+		{0, 0},
+		{0.5, 18},
+		{0.9999999, 36},
+		{1, 36},
+	}
+
+	for _, tt := range tests {
+		if got := Pocket(tt.r); got != tt.want {
+			t.Errorf("Pocket(%v) = %d, want %d", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestMaxPayout(t *testing.T) {
+	bets := []Bet{
+		{Type: Straight, Numbers: []int{1}, Amount: 10},
+		{Type: Red, Amount: 10},
+		{Type: Black, Amount: 10},
+	}
+
+	got, err := MaxPayout(bets)
+	if err != nil {
+		t.Fatalf("MaxPayout() failed: %v", err)
+	}
+	// 1 is red, so the straight and the red bet both pay on it.
+	if want := 380.0; got != want {
+		t.Errorf("MaxPayout() = %v, want %v", got, want)
+	}
+
+	if _, err := MaxPayout([]Bet{{Type: Split, Numbers: []int{1, 9}, Amount: 10}}); err == nil {
+		t.Error("MaxPayout() accepted an invalid bet")
+	}
+}
@@ -0,0 +1,174 @@
+// Package slots implements a data-driven slot machine. Reel strips, symbols,
+// paylines and the free spin feature all come from a Config, which can be
+// written as JSON or YAML.
+package slots
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// DefaultMaxFreeSpins caps the free spins of one feature, retriggers
+// included, when the config doesn't set its own cap.
+const DefaultMaxFreeSpins = 100
+
+type Symbol struct {
+	ID string `json:"id" yaml:"id"`
+	// Pays maps a number of symbols in a row, from the leftmost reel, to a
+	// multiplier of the line bet. For scatters it is a multiplier of the total
+	// bet for that many scatters anywhere on the screen.
+	Pays    map[int]float64 `json:"pays" yaml:"pays"`
+	Wild    bool            `json:"wild,omitempty" yaml:"wild,omitempty"`
+	Scatter bool            `json:"scatter,omitempty" yaml:"scatter,omitempty"`
+}
+
+type FreeSpins struct {
+	// Awards maps a number of scatters to the free spins they award.
+	Awards     map[int]int `json:"awards" yaml:"awards"`
+	Multiplier float64     `json:"multiplier" yaml:"multiplier"`
+	MaxSpins   int         `json:"max_spins,omitempty" yaml:"max_spins,omitempty"`
+}
+
+type Config struct {
+	Name    string     `json:"name" yaml:"name"`
+	Rows    int        `json:"rows" yaml:"rows"`
+	Reels   [][]string `json:"reels" yaml:"reels"`
+	Symbols []Symbol   `json:"symbols" yaml:"symbols"`
+	// Paylines lists, per line, the row of every reel the line runs through.
+	Paylines  [][]int    `json:"paylines" yaml:"paylines"`
+	FreeSpins *FreeSpins `json:"free_spins,omitempty" yaml:"free_spins,omitempty"`
+	MinBet    float64    `json:"min_bet" yaml:"min_bet"`
+	MaxBet    float64    `json:"max_bet" yaml:"max_bet"`
+
+	symbols map[string]Symbol
+}
+
+// Parse decodes and validates a config written in format.
+func Parse(data []byte, format string) (*Config, error) {
+	var config Config
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate checks that every reel, payline and feature refers to things that
+// exist, and indexes the symbols for the evaluator.
+func (c *Config) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	if c.Rows < 1 || c.Rows > 10 {
+		return errors.New("rows must be between 1 and 10")
+	}
+	if len(c.Reels) < 3 || len(c.Reels) > 10 {
+		return errors.New("a machine needs between 3 and 10 reels")
+	}
+	if c.MinBet <= 0 || c.MaxBet < c.MinBet {
+		return errors.New("min_bet must be positive and not above max_bet")
+	}
+
+	c.symbols = make(map[string]Symbol)
+	scatters := 0
+	for _, symbol := range c.Symbols {
+		if symbol.ID == "" {
+			return errors.New("every symbol needs an id")
+		}
+		if _, ok := c.symbols[symbol.ID]; ok {
+			return fmt.Errorf("symbol %q is defined twice", symbol.ID)
+		}
+		if symbol.Wild && symbol.Scatter {
+			return fmt.Errorf("symbol %q can't be both wild and scatter", symbol.ID)
+		}
+		for count, pay := range symbol.Pays {
+			if count < 1 || count > len(c.Reels) || pay < 0 {
+				return fmt.Errorf("symbol %q has an invalid pay for %d", symbol.ID, count)
+			}
+		}
+		if symbol.Scatter {
+			scatters++
+		}
+		c.symbols[symbol.ID] = symbol
+	}
+	if scatters > 1 {
+		return errors.New("at most one scatter symbol is supported")
+	}
+
+	for i, reel := range c.Reels {
+		if len(reel) < c.Rows {
+			return fmt.Errorf("reel %d is shorter than the number of rows", i+1)
+		}
+		for _, id := range reel {
+			if _, ok := c.symbols[id]; !ok {
+				return fmt.Errorf("reel %d uses unknown symbol %q", i+1, id)
+			}
+		}
+	}
+
+	if len(c.Paylines) == 0 {
+		return errors.New("at least one payline is required")
+	}
+	for i, line := range c.Paylines {
+		if len(line) != len(c.Reels) {
+			return fmt.Errorf("payline %d must have one row per reel", i+1)
+		}
+		for _, row := range line {
+			if row < 0 || row >= c.Rows {
+				return fmt.Errorf("payline %d points outside the screen", i+1)
+			}
+		}
+	}
+
+	if c.FreeSpins != nil {
+		if scatters == 0 {
+			return errors.New("free spins need a scatter symbol")
+		}
+		if c.FreeSpins.Multiplier <= 0 {
+			c.FreeSpins.Multiplier = 1
+		}
+		if c.FreeSpins.MaxSpins <= 0 {
+			c.FreeSpins.MaxSpins = DefaultMaxFreeSpins
+		}
+		for count, spins := range c.FreeSpins.Awards {
+			if count < 1 || spins < 0 {
+				return fmt.Errorf("invalid free spin award for %d scatters", count)
+			}
+		}
+	}
+
+	return nil
+}
+
+// MaxLinePay returns the highest multiplier of the line bet any symbol pays.
+func (c *Config) MaxLinePay() float64 {
+	var max float64
+	for _, symbol := range c.Symbols {
+		for _, pay := range symbol.Pays {
+			if !symbol.Scatter && pay > max {
+				max = pay
+			}
+		}
+	}
+	return max
+}
@@ -0,0 +1,158 @@
+package slots
+
+// RNG returns the next uniform value in [0, 1). Spins take as many values as
+// they need, one per reel stop.
+type RNG func() float64
+
+type LineWin struct {
+	Line       int     `json:"line"`
+	Symbol     string  `json:"symbol"`
+	Count      int     `json:"count"`
+	Multiplier float64 `json:"multiplier"`
+	Win        float64 `json:"win"`
+}
+
+type SpinResult struct {
+	Stops            []int      `json:"stops"`
+	Screen           [][]string `json:"screen"` // one column of visible symbols per reel
+	LineWins         []LineWin  `json:"line_wins"`
+	Scatters         int        `json:"scatters"`
+	ScatterWin       float64    `json:"scatter_win"`
+	FreeSpinsAwarded int        `json:"free_spins_awarded"`
+	Multiplier       float64    `json:"multiplier"`
+	Win              float64    `json:"win"`
+}
+
+// Outcome is a paid spin together with every free spin it led to.
+type Outcome struct {
+	Bet       float64      `json:"bet"`
+	Base      SpinResult   `json:"base"`
+	FreeSpins []SpinResult `json:"free_spins,omitempty"`
+	TotalWin  float64      `json:"total_win"`
+}
+
+// Play spins the reels for a total bet spread evenly over the paylines and
+// plays out any free spins it triggers.
+func (c *Config) Play(bet float64, rng RNG) Outcome {
+	outcome := Outcome{Bet: bet}
+	outcome.Base = c.spin(bet, 1, rng)
+	outcome.TotalWin = outcome.Base.Win
+
+	if c.FreeSpins == nil {
+		return outcome
+	}
+
+	// The base award counts towards the feature cap like any retrigger.
+	if outcome.Base.FreeSpinsAwarded > c.FreeSpins.MaxSpins {
+		outcome.Base.FreeSpinsAwarded = c.FreeSpins.MaxSpins
+	}
+	remaining := outcome.Base.FreeSpinsAwarded
+	granted := remaining
+	for remaining > 0 {
+		result := c.spin(bet, c.FreeSpins.Multiplier, rng)
+		remaining--
+
+		// Retriggers add spins until the feature cap is reached.
+		extra := result.FreeSpinsAwarded
+		if granted+extra > c.FreeSpins.MaxSpins {
+			extra = c.FreeSpins.MaxSpins - granted
+		}
+		result.FreeSpinsAwarded = extra
+		granted += extra
+		remaining += extra
+
+		outcome.FreeSpins = append(outcome.FreeSpins, result)
+		outcome.TotalWin += result.Win
+	}
+
+	return outcome
+}
+
+func (c *Config) spin(bet, multiplier float64, rng RNG) SpinResult {
+	result := SpinResult{Multiplier: multiplier}
+
+	for _, reel := range c.Reels {
+		stop := int(rng() * float64(len(reel)))
+		if stop >= len(reel) {
+			stop = len(reel) - 1
+		}
+		column := make([]string, c.Rows)
+		for row := range column {
+			column[row] = reel[(stop+row)%len(reel)]
+		}
+		result.Stops = append(result.Stops, stop)
+		result.Screen = append(result.Screen, column)
+	}
+
+	lineBet := bet / float64(len(c.Paylines))
+	for i, line := range c.Paylines {
+		symbols := make([]string, len(line))
+		for reel, row := range line {
+			symbols[reel] = result.Screen[reel][row]
+		}
+		if win, ok := c.evaluateLine(symbols); ok {
+			win.Line = i + 1
+			win.Win = lineBet * win.Multiplier * multiplier
+			result.LineWins = append(result.LineWins, win)
+			result.Win += win.Win
+		}
+	}
+
+	for _, symbol := range c.Symbols {
+		if !symbol.Scatter {
+			continue
+		}
+		for _, column := range result.Screen {
+			for _, id := range column {
+				if id == symbol.ID {
+					result.Scatters++
+				}
+			}
+		}
+		result.ScatterWin = bet * symbol.Pays[result.Scatters] * multiplier
+		result.Win += result.ScatterWin
+		if c.FreeSpins != nil {
+			result.FreeSpinsAwarded = c.FreeSpins.Awards[result.Scatters]
+		}
+	}
+
+	return result
+}
+
+// evaluateLine pays the longest run from the leftmost reel. Wilds stand in for
+// the first regular symbol of the line; a run of wilds alone pays the wild's
+// own table when that is worth more.
+func (c *Config) evaluateLine(symbols []string) (LineWin, bool) {
+	wildRun := 0
+	for _, id := range symbols {
+		if !c.symbols[id].Wild {
+			break
+		}
+		wildRun++
+	}
+
+	best := LineWin{}
+	if wildRun > 0 {
+		wild := c.symbols[symbols[0]]
+		best = LineWin{Symbol: wild.ID, Count: wildRun, Multiplier: wild.Pays[wildRun]}
+	}
+
+	if wildRun < len(symbols) {
+		target := c.symbols[symbols[wildRun]]
+		if !target.Scatter {
+			count := wildRun
+			for _, id := range symbols[wildRun:] {
+				symbol := c.symbols[id]
+				if id != target.ID && !symbol.Wild {
+					break
+				}
+				count++
+			}
+			if pay := target.Pays[count]; pay > best.Multiplier {
+				best = LineWin{Symbol: target.ID, Count: count, Multiplier: pay}
+			}
+		}
+	}
+
+	return best, best.Multiplier > 0
+}
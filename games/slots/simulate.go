@@ -0,0 +1,50 @@
+package slots
+
+import (
+	"math/rand"
+)
+
+type Simulation struct {
+	Spins            int     `json:"spins"`
+	RTP              float64 `json:"rtp"` // percentage of the total bet paid back
+	HitRate          float64 `json:"hit_rate"`
+	FreeSpinRate     float64 `json:"free_spin_rate"`
+	BaseGameRTP      float64 `json:"base_game_rtp"`
+	FreeSpinRTP      float64 `json:"free_spin_rtp"`
+	MaxWinMultiplier float64 `json:"max_win_multiplier"`
+}
+
+// Simulate plays spins paid spins of one unit with a pseudo random source
+// seeded with seed and reports the return of the config.
+func (c *Config) Simulate(spins int, seed int64) Simulation {
+	source := rand.New(rand.NewSource(seed))
+	rng := func() float64 { return source.Float64() }
+
+	sim := Simulation{Spins: spins}
+	var total, base float64
+	hits, triggers := 0, 0
+	for i := 0; i < spins; i++ {
+		outcome := c.Play(1, rng)
+		total += outcome.TotalWin
+		base += outcome.Base.Win
+		if outcome.TotalWin > 0 {
+			hits++
+		}
+		if len(outcome.FreeSpins) > 0 {
+			triggers++
+		}
+		if outcome.TotalWin > sim.MaxWinMultiplier {
+			sim.MaxWinMultiplier = outcome.TotalWin
+		}
+	}
+
+	if spins > 0 {
+		n := float64(spins)
+		sim.RTP = total / n * 100
+		sim.BaseGameRTP = base / n * 100
+		sim.FreeSpinRTP = sim.RTP - sim.BaseGameRTP
+		sim.HitRate = float64(hits) / n * 100
+		sim.FreeSpinRate = float64(triggers) / n * 100
+	}
+	return sim
+}
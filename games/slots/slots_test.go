@@ -0,0 +1,185 @@
+package slots
+
+import (
+	"strings"
+	"testing"
+)
+
+const testReelSize = 4
+
+// testConfig is a one row machine with a single payline. Every reel holds the
+// same four symbols, so a spin is picked by the stop of each reel.
+const testConfig = `
+name: test
+rows: 1
+min_bet: 1
+max_bet: 100
+reels:
+  - [A, B, W, S]
+  - [A, B, W, S]
+  - [A, B, W, S]
+symbols:
+  - id: A
+    pays: {2: 2, 3: 10}
+  - id: B
+    pays: {3: 5}
+  - id: W
+    wild: true
+    pays: {3: 50}
+  - id: S
+    scatter: true
+    pays: {3: 2}
+paylines:
+  - [0, 0, 0]
+free_spins:
+  awards: {3: 2}
+  multiplier: 3
+  max_spins: 4
+`
+
+func parseTestConfig(t *testing.T) *Config {
+	t.Helper()
+	config, err := Parse([]byte(testConfig), FormatYAML)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return config
+}
+
+// reelStops returns an RNG that stops the reels on the given symbols, one per
+// reel in play order, and fails the test if the machine asks for more.
+func reelStops(t *testing.T, screen string) RNG {
+	t.Helper()
+	symbols := strings.Fields(screen)
+	return func() float64 {
+		if len(symbols) == 0 {
+			t.Fatal("RNG ran out of stops")
+		}
+		stop := strings.Index("ABWS", symbols[0])
+		symbols = symbols[1:]
+		return (float64(stop) + 0.5) / testReelSize
+	}
+}
+
+func TestSpinLineWins(t *testing.T) {
+	config := parseTestConfig(t)
+	config.FreeSpins = nil
+
+	tests := []struct {
+		screen string
+		symbol string
+		count  int
+		win    float64
+	}{
+		{"A A A", "A", 3, 100},
+		{"A A B", "A", 2, 20},
+		{"W A A", "A", 3, 100},
+		{"W W A", "A", 3, 100},
+		{"W W W", "W", 3, 500},
+		{"W W B", "B", 3, 50},
+		{"B A A", "", 0, 0},
+		{"A B A", "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.screen, func(t *testing.T) {
+			outcome := config.Play(10, reelStops(t, tt.screen))
+
+			if outcome.TotalWin != tt.win {
+				t.Errorf("TotalWin = %v, want %v", outcome.TotalWin, tt.win)
+			}
+			if tt.count == 0 {
+				if len(outcome.Base.LineWins) != 0 {
+					t.Errorf("LineWins = %+v, want none", outcome.Base.LineWins)
+				}
+				return
+			}
+			if len(outcome.Base.LineWins) != 1 {
+				t.Fatalf("LineWins = %+v, want one", outcome.Base.LineWins)
+			}
+			if win := outcome.Base.LineWins[0]; win.Symbol != tt.symbol || win.Count != tt.count {
+				t.Errorf("line paid %d %s, want %d %s", win.Count, win.Symbol, tt.count, tt.symbol)
+			}
+		})
+	}
+}
+
+func TestFreeSpins(t *testing.T) {
+	config := parseTestConfig(t)
+
+	// Three scatters award two free spins. The second free spin retriggers two
+	// more, and the last retrigger is cut by the cap of four.
+	outcome := config.Play(10, reelStops(t, "S S S  A A A  S S S  B B B  S S S"))
+
+	if outcome.Base.Scatters != 3 || outcome.Base.ScatterWin != 20 || outcome.Base.FreeSpinsAwarded != 2 {
+		t.Errorf("base spin paid %v for %d scatters and awarded %d free spins, want 20, 3 and 2",
+			outcome.Base.ScatterWin, outcome.Base.Scatters, outcome.Base.FreeSpinsAwarded)
+	}
+	if len(outcome.FreeSpins) != 4 {
+		t.Fatalf("played %d free spins, want 4", len(outcome.FreeSpins))
+	}
+
+	wantWins := []float64{300, 60, 150, 60}
+	wantAwards := []int{0, 2, 0, 0}
+	for i, spin := range outcome.FreeSpins {
+		if spin.Win != wantWins[i] || spin.FreeSpinsAwarded != wantAwards[i] {
+			t.Errorf("free spin %d won %v and awarded %d, want %v and %d", i+1, spin.Win, spin.FreeSpinsAwarded, wantWins[i], wantAwards[i])
+		}
+	}
+	if outcome.TotalWin != 590 {
+		t.Errorf("TotalWin = %v, want 590", outcome.TotalWin)
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	json := `{"name":"test","rows":1,"min_bet":1,"max_bet":10,` +
+		`"reels":[["A","B"],["A","B"],["A","B"]],` +
+		`"symbols":[{"id":"A","pays":{"3":5}},{"id":"B","pays":{"3":2}}],` +
+		`"paylines":[[0,0,0]]}`
+
+	config, err := Parse([]byte(json), FormatJSON)
+	if err != nil {
+		t.Fatalf("Parse(json) failed: %v", err)
+	}
+	if got := config.MaxLinePay(); got != 5 {
+		t.Errorf("MaxLinePay() = %v, want 5", got)
+	}
+
+	if _, err := Parse([]byte(json), "toml"); err == nil {
+		t.Error("Parse accepted an unsupported format")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+	}{
+		{"no name", func(c *Config) { c.Name = "" }},
+		{"no rows", func(c *Config) { c.Rows = 0 }},
+		{"two reels", func(c *Config) { c.Reels = c.Reels[:2] }},
+		{"max bet below min bet", func(c *Config) { c.MaxBet = 0.5 }},
+		{"unknown symbol on a reel", func(c *Config) { c.Reels[1] = []string{"A", "X"} }},
+		{"duplicate symbol", func(c *Config) { c.Symbols = append(c.Symbols, Symbol{ID: "A"}) }},
+		{"wild scatter", func(c *Config) { c.Symbols[2].Scatter = true }},
+		{"pay for more symbols than reels", func(c *Config) { c.Symbols[0].Pays = map[int]float64{4: 10} }},
+		{"no paylines", func(c *Config) { c.Paylines = nil }},
+		{"payline off the screen", func(c *Config) { c.Paylines = [][]int{{0, 1, 0}} }},
+		{"free spins without a scatter", func(c *Config) {
+			c.Symbols = c.Symbols[:3]
+			for i := range c.Reels {
+				c.Reels[i] = []string{"A", "B", "W"}
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := parseTestConfig(t)
+			tt.mutate(config)
+			if err := config.Validate(); err == nil {
+				t.Error("Validate() accepted an invalid config")
+			}
+		})
+	}
+}
@@ -34,6 +34,11 @@ func main() {
 	routes.SetupProtectedRoutes(router)
 	routes.SetupAdminRoutes(router)
 	routes.SetupCasinoRoutes(router)
+	routes.SetupGameRoutes(router)
+	routes.SetupJackpotRoutes(router)
+
+	controllers.StartRoundScheduler()
+	controllers.StartKenoScheduler()
 
 	go func() {
 		ticker := time.NewTicker(24 * time.Hour)
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type BlackjackSettings struct {
+	gorm.Model
+	MinBetAmount     float64 `gorm:"not null;default:1000"`
+	MaxBetAmount     float64 `gorm:"not null;default:1000000"`
+	Decks            int     `gorm:"not null;default:6"`
+	Penetration      float64 `gorm:"not null;default:0.75"` // share of the shoe dealt before it is reshuffled
+	DealerHitsSoft17 bool    `gorm:"not null;default:false"`
+	IsActive         bool    `gorm:"not null;default:true"`
+}
+
+type BlackjackShoe struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	Decks    int    `gorm:"not null"`
+	Cards    string `gorm:"type:mediumtext;not null"` // JSON list of cards (0-51) in draw order
+	Position int    `gorm:"not null;default:0"`
+	CutCard  int    `gorm:"not null"`
+	Status   string `gorm:"type:enum('active', 'retired');default:'active';index"`
+	User     *User  `gorm:"belongsTo:User"`
+
+	ServerSeedID   *uint       `gorm:"null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	Nonce          uint64      `gorm:"not null;default:0"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
+}
+
+type BlackjackGame struct {
+	gorm.Model
+	UserID     uint           `gorm:"not null;index"`
+	ShoeID     uint           `gorm:"not null;index"`
+	BetAmount  float64        `gorm:"not null"`
+	TotalStake float64        `gorm:"not null"` // bet plus any double, split or insurance stakes
+	WinAmount  float64        `gorm:"not null;default:0"`
+	Capped     bool           `gorm:"default:false"`      // win was cut to the max win per bet
+	State      string         `gorm:"type:text;not null"` // JSON of the hands, dealer cards and phase
+	Status     string         `gorm:"type:enum('active', 'settled');default:'active';index"`
+	User       *User          `gorm:"belongsTo:User"`
+	Shoe       *BlackjackShoe `gorm:"belongsTo:BlackjackShoe"`
+}
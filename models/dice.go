@@ -0,0 +1,35 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type DiceSettings struct {
+	gorm.Model
+	MinBetAmount float64 `gorm:"not null;default:1000"`
+	MaxBetAmount float64 `gorm:"not null;default:1000000"`
+	HouseEdge    float64 `gorm:"not null;default:1.0"`
+	MinWinChance float64 `gorm:"not null;default:1.0"`
+	MaxWinChance float64 `gorm:"not null;default:95.0"`
+	IsActive     bool    `gorm:"not null;default:true"`
+}
+
+type DiceRoll struct {
+	gorm.Model
+	UserID     uint    `gorm:"not null;index"`
+	BetAmount  float64 `gorm:"not null"`
+	Target     float64 `gorm:"not null"`
+	Direction  string  `gorm:"type:enum('over', 'under');not null"`
+	Roll       float64 `gorm:"not null"`
+	WinChance  float64 `gorm:"not null"`
+	Multiplier float64 `gorm:"not null"`
+	WinAmount  float64 `gorm:"not null;default:0"`
+	Status     string  `gorm:"type:enum('won', 'lost');not null"`
+	User       *User   `gorm:"belongsTo:User"`
+
+	ServerSeedID   *uint       `gorm:"null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	Nonce          uint64      `gorm:"not null;default:0"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
+}
@@ -1,7 +1,7 @@
 package models
 
 import (
-	"sync/atomic"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -9,23 +9,30 @@ import (
 type Game struct {
 	gorm.Model
 	UserID      uint    `gorm:"not null"`
+	GameType    string  `gorm:"type:enum('crash', 'limbo');default:'crash';index"`
 	BetAmount   float64 `gorm:"not null"`
 	Multiplier  float64 `gorm:"not null;default:1.0"`
 	WinAmount   float64 `gorm:"not null;default:0"`
 	CrashPoint  float64 `gorm:"not null;default:0"`
-	Status      string  `gorm:"type:enum('active', 'won', 'lost');default:'active'"`
+	Status      string  `gorm:"type:enum('active', 'won', 'lost', 'refunded');default:'active'"`
 	IsCompleted bool    `gorm:"not null;default:false"`
-	User        *User   `gorm:"belongsTo:User"`
+	RoundID     *uint   `gorm:"index"`
 
-	completedFlag int32 `gorm:"-"`
-}
+	// SettingsSnapshot holds the GameSettings the game is played with, as
+	// JSON, so admin changes only apply to games started afterwards.
+	SettingsSnapshot string `gorm:"type:text"`
 
-func (g *Game) TryCompleteGame() bool {
-	return atomic.CompareAndSwapInt32(&g.completedFlag, 0, 1)
-}
+	AutoCashoutAt  *float64      `gorm:"null"`
+	CashedOutStake float64       `gorm:"not null;default:0"`
+	User           *User         `gorm:"belongsTo:User"`
+	Round          *Round        `gorm:"belongsTo:Round"`
+	Cashouts       []GameCashout `gorm:"foreignKey:GameID"`
 
-func (g *Game) IsCompletedAtomically() bool {
-	return atomic.LoadInt32(&g.completedFlag) == 1
+	ServerSeedID   *uint       `gorm:"null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	Nonce          uint64      `gorm:"not null;default:0"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
 }
 
 type GameSettings struct {
@@ -35,4 +42,45 @@ type GameSettings struct {
 	MaxBetAmount    float64 `gorm:"not null;default:1000000"`
 	MultiplierSpeed float64 `gorm:"not null;default:0.1"`
 	IsActive        bool    `gorm:"not null;default:true"`
+
+	HouseEdge          float64 `gorm:"not null;default:1.0"`
+	InstantCrashChance float64 `gorm:"not null;default:0"`
+	Distribution       string  `gorm:"type:enum('standard', 'pareto');default:'standard'"`
+	DistributionShape  float64 `gorm:"not null;default:2.0"`
+
+	Curve       string `gorm:"type:enum('linear', 'exponential', 'piecewise');default:'linear'"`
+	CurvePoints string `gorm:"type:text"`
+
+	MaxWinPerBet      float64 `gorm:"not null;default:0"` // 0 disables the cap
+	MaxRoundLiability float64 `gorm:"not null;default:0"` // 0 disables the limit
+}
+
+// GameSettingsVersion is an append-only record of a change to GameSettings.
+// PreviousSettings and Settings hold the full settings before and after the
+// change as JSON; PreviousSettings is empty for the first version.
+type GameSettingsVersion struct {
+	gorm.Model
+	SettingsID       uint   `gorm:"not null;index"`
+	AdminID          *uint  `gorm:"index"`
+	Action           string `gorm:"type:enum('seed', 'update', 'scheduled', 'rollback');default:'update'"`
+	Reason           string `gorm:"size:255"`
+	RolledBackTo     *uint  `gorm:"null"`
+	PreviousSettings string `gorm:"type:text"`
+	Settings         string `gorm:"type:text;not null"`
+	Admin            *User  `gorm:"foreignKey:AdminID"`
+}
+
+// ScheduledSettingsChange is a GameSettings update that the scheduler applies
+// at EffectiveAt. Request holds the update as it was submitted, as JSON.
+type ScheduledSettingsChange struct {
+	gorm.Model
+	AdminID     *uint      `gorm:"index"`
+	EffectiveAt time.Time  `gorm:"not null;index"`
+	Reason      string     `gorm:"size:255"`
+	Request     string     `gorm:"type:text;not null"`
+	Status      string     `gorm:"type:enum('pending', 'applied', 'cancelled', 'failed');default:'pending';index"`
+	AppliedAt   *time.Time `gorm:"null"`
+	VersionID   *uint      `gorm:"null"`
+	Error       string     `gorm:"size:255"`
+	Admin       *User      `gorm:"foreignKey:AdminID"`
 }
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type GameCashout struct {
+	gorm.Model
+	GameID        uint         `gorm:"not null;index"`
+	UserID        uint         `gorm:"not null;index"`
+	Fraction      float64      `gorm:"not null"`
+	Stake         float64      `gorm:"not null"`
+	Multiplier    float64      `gorm:"not null"`
+	WinAmount     float64      `gorm:"not null"`
+	TransactionID *uint        `gorm:"null"`
+	Game          *Game        `gorm:"belongsTo:Game"`
+	Transaction   *Transaction `gorm:"belongsTo:Transaction"`
+}
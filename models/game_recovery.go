@@ -0,0 +1,19 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type GameRecovery struct {
+	gorm.Model
+	GameID    uint    `gorm:"not null;index"`
+	RoundID   *uint   `gorm:"index"`
+	UserID    uint    `gorm:"not null;index"`
+	Policy    string  `gorm:"type:enum('resume', 'crash', 'refund');not null"`
+	Action    string  `gorm:"type:enum('resumed', 'won', 'lost', 'refunded');not null"`
+	BetAmount float64 `gorm:"not null"`
+	Amount    float64 `gorm:"not null;default:0"`
+	Note      string  `gorm:"null"`
+	Game      *Game   `gorm:"belongsTo:Game"`
+	User      *User   `gorm:"belongsTo:User"`
+}
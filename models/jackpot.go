@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JackpotPool is the progressive jackpot fed by a share of every crash bet.
+// There is a single row. ContributionRate and TriggerChance are percentages;
+// after a win the pool starts again from SeedAmount, paid by the house.
+type JackpotPool struct {
+	gorm.Model
+	Balance          float64    `gorm:"not null;default:0"`
+	SeedAmount       float64    `gorm:"not null;default:0"`
+	ContributionRate float64    `gorm:"not null;default:0"`
+	TriggerChance    float64    `gorm:"not null;default:0"`
+	MinBetAmount     float64    `gorm:"not null;default:0"`
+	IsActive         bool       `gorm:"not null;default:false"`
+	TotalContributed float64    `gorm:"not null;default:0"`
+	TotalSeeded      float64    `gorm:"not null;default:0"`
+	TotalPaid        float64    `gorm:"not null;default:0"`
+	LastWonAt        *time.Time `gorm:"null"`
+}
+
+// JackpotEntry is the jackpot side of one crash bet: the share of the bet that
+// went into the pool and the provably fair draw the bet took part in.
+type JackpotEntry struct {
+	gorm.Model
+	GameID         uint        `gorm:"not null;uniqueIndex"`
+	UserID         uint        `gorm:"not null;index"`
+	Contribution   float64     `gorm:"not null;default:0"`
+	PoolBalance    float64     `gorm:"not null;default:0"` // pool after the bet, before any win was paid
+	TriggerChance  float64     `gorm:"not null;default:0"`
+	Roll           float64     `gorm:"not null;default:0"`
+	Won            bool        `gorm:"not null;default:false;index"`
+	WinAmount      float64     `gorm:"not null;default:0"`
+	ServerSeedID   uint        `gorm:"not null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	Nonce          uint64      `gorm:"not null;default:0"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
+	User           *User       `gorm:"belongsTo:User"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type KenoSettings struct {
+	gorm.Model
+	MinBetAmount float64 `gorm:"not null;default:1000"`
+	MaxBetAmount float64 `gorm:"not null;default:1000000"`
+	DrawInterval int     `gorm:"not null;default:60"` // seconds between draws
+	Paytable     string  `gorm:"type:text;not null"`  // JSON of multipliers per hits, keyed by picks
+	IsActive     bool    `gorm:"not null;default:true"`
+}
+
+type KenoDraw struct {
+	gorm.Model
+	Status       string     `gorm:"type:enum('open', 'drawn', 'settled');default:'open';index"`
+	DrawAt       time.Time  `gorm:"not null;index"`
+	Numbers      string     `gorm:"type:text"` // JSON list of the 20 drawn numbers
+	DrawnAt      *time.Time `gorm:"null"`
+	SettledAt    *time.Time `gorm:"null"`
+	TotalTickets int64      `gorm:"default:0"`
+	TotalWagered float64    `gorm:"default:0"`
+	TotalPayout  float64    `gorm:"default:0"`
+
+	ServerSeedID   *uint       `gorm:"null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
+}
+
+type KenoTicket struct {
+	gorm.Model
+	DrawID     uint      `gorm:"not null;index"`
+	UserID     uint      `gorm:"not null;index"`
+	Picks      string    `gorm:"type:text;not null"` // JSON list of picked numbers
+	Payouts    string    `gorm:"type:text;not null"` // JSON paytable row for the number of picks, fixed at purchase
+	BetAmount  float64   `gorm:"not null"`
+	Hits       int       `gorm:"default:0"`
+	Multiplier float64   `gorm:"default:0"`
+	WinAmount  float64   `gorm:"default:0"`
+	Capped     bool      `gorm:"default:false"` // win was cut to the max win per bet
+	Status     string    `gorm:"type:enum('pending', 'won', 'lost');default:'pending';index"`
+	Draw       *KenoDraw `gorm:"belongsTo:KenoDraw"`
+	User       *User     `gorm:"belongsTo:User"`
+}
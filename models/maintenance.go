@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaintenanceWindow pauses new bets between StartsAt and EndsAt. Games that
+// are already running are played out.
+type MaintenanceWindow struct {
+	gorm.Model
+	StartsAt    time.Time  `gorm:"not null;index"`
+	EndsAt      time.Time  `gorm:"not null;index"`
+	Message     string     `gorm:"size:255"`
+	AdminID     *uint      `gorm:"index"`
+	CancelledAt *time.Time `gorm:"null"`
+	Admin       *User      `gorm:"foreignKey:AdminID"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type MinesSettings struct {
+	gorm.Model
+	MinBetAmount float64 `gorm:"not null;default:1000"`
+	MaxBetAmount float64 `gorm:"not null;default:1000000"`
+	HouseEdge    float64 `gorm:"not null;default:1.0"`
+	MinMines     int     `gorm:"not null;default:1"`
+	MaxMines     int     `gorm:"not null;default:24"`
+	IsActive     bool    `gorm:"not null;default:true"`
+}
+
+type MinesGame struct {
+	gorm.Model
+	UserID        uint    `gorm:"not null;index"`
+	BetAmount     float64 `gorm:"not null"`
+	MineCount     int     `gorm:"not null"`
+	HouseEdge     float64 `gorm:"not null"`
+	MinePositions string  `gorm:"type:text;not null"` // JSON list of tiles, revealed once the game ends
+	RevealedTiles string  `gorm:"type:text"`          // JSON list of tiles in reveal order
+	Multiplier    float64 `gorm:"not null;default:1.0"`
+	WinAmount     float64 `gorm:"not null;default:0"`
+	Capped        bool    `gorm:"default:false"` // win was cut to the max win per bet
+	Status        string  `gorm:"type:enum('active', 'won', 'lost');default:'active';index"`
+	User          *User   `gorm:"belongsTo:User"`
+
+	ServerSeedID   *uint       `gorm:"null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	Nonce          uint64      `gorm:"not null;default:0"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type PlinkoPayoutTable struct {
+	gorm.Model
+	Rows        int    `gorm:"not null;uniqueIndex:idx_plinko_rows_risk"`
+	Risk        string `gorm:"type:enum('low', 'medium', 'high');not null;uniqueIndex:idx_plinko_rows_risk"`
+	Multipliers string `gorm:"type:text;not null"` // JSON list with one multiplier per slot
+}
+
+type PlinkoDrop struct {
+	gorm.Model
+	UserID     uint    `gorm:"not null;index"`
+	BetAmount  float64 `gorm:"not null"`
+	Rows       int     `gorm:"not null"`
+	Risk       string  `gorm:"type:enum('low', 'medium', 'high');not null"`
+	Path       string  `gorm:"type:text;not null"` // JSON list of 0 (left) and 1 (right) per row
+	Slot       int     `gorm:"not null"`
+	Multiplier float64 `gorm:"not null"`
+	WinAmount  float64 `gorm:"not null;default:0"`
+	User       *User   `gorm:"belongsTo:User"`
+
+	ServerSeedID   *uint       `gorm:"null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	Nonce          uint64      `gorm:"not null;default:0"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
+}
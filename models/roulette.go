@@ -0,0 +1,34 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type RouletteSpin struct {
+	gorm.Model
+	UserID      uint          `gorm:"not null;index"`
+	Result      int           `gorm:"not null"`
+	Color       string        `gorm:"type:enum('green', 'red', 'black');not null"`
+	TotalStake  float64       `gorm:"not null"`
+	TotalPayout float64       `gorm:"not null;default:0"`
+	User        *User         `gorm:"belongsTo:User"`
+	Bets        []RouletteBet `gorm:"foreignKey:SpinID"`
+
+	ServerSeedID   *uint       `gorm:"null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	Nonce          uint64      `gorm:"not null;default:0"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
+}
+
+type RouletteBet struct {
+	gorm.Model
+	SpinID  uint    `gorm:"not null;index"`
+	UserID  uint    `gorm:"not null;index"`
+	Type    string  `gorm:"type:enum('straight', 'split', 'street', 'corner', 'dozen', 'column', 'red', 'black', 'odd', 'even');not null"`
+	Numbers string  `gorm:"type:text"` // JSON list of the numbers picked on the slip
+	Covered string  `gorm:"type:text"` // JSON list of every number the bet covers
+	Amount  float64 `gorm:"not null"`
+	Won     bool    `gorm:"default:false"`
+	Payout  float64 `gorm:"not null;default:0"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Round struct {
+	gorm.Model
+	Status        string     `gorm:"type:enum('betting', 'running', 'crashed');default:'betting';index"`
+	CrashPoint    float64    `gorm:"not null;default:0"`
+	BettingEndsAt time.Time  `gorm:"not null"`
+	StartedAt     *time.Time `gorm:"null"`
+	CrashedAt     *time.Time `gorm:"null"`
+	TotalBets     int64      `gorm:"not null;default:0"`
+	TotalWagered  float64    `gorm:"not null;default:0"`
+	TotalPayout   float64    `gorm:"not null;default:0"`
+
+	// SettingsSnapshot holds the GameSettings the round was opened with, as
+	// JSON. Its crash point, curve and the games placed in it all use it.
+	SettingsSnapshot string `gorm:"type:text"`
+
+	ServerSeedID   *uint       `gorm:"null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
+	Games          []Game      `gorm:"hasMany:Game"`
+}
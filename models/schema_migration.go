@@ -0,0 +1,12 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// SchemaMigration records a one-off data migration that has been applied, so
+// it does not run again on the next boot.
+type SchemaMigration struct {
+	gorm.Model
+	Name string `gorm:"size:100;not null;uniqueIndex"`
+}
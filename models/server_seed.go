@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ServerSeed struct {
+	gorm.Model
+	Seed       string     `gorm:"size:64;not null;uniqueIndex"`
+	Hash       string     `gorm:"size:64;not null;index"`
+	UserID     *uint      `gorm:"index"`
+	RoundID    *uint      `gorm:"index"`
+	KenoDrawID *uint      `gorm:"index"`
+	UsedAt     *time.Time `gorm:"null"`
+	RetiredAt  *time.Time `gorm:"null"` // replaced before it was played and revealed to its owner
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SlotMachine struct {
+	gorm.Model
+	Name       string `gorm:"size:100;not null;uniqueIndex"`
+	Format     string `gorm:"type:enum('json', 'yaml');not null"`
+	Config     string `gorm:"type:mediumtext;not null"` // config as uploaded
+	ConfigHash string `gorm:"size:64;not null"`
+	MinBet     float64
+	MaxBet     float64
+	Status     string `gorm:"type:enum('draft', 'simulated', 'active', 'inactive');default:'draft';index"`
+
+	SimulatedRTP     *float64
+	SimulatedHitRate *float64
+	SimulationSpins  int
+	SimulatedAt      *time.Time
+}
+
+type SlotSpin struct {
+	gorm.Model
+	UserID     uint         `gorm:"not null;index"`
+	MachineID  uint         `gorm:"not null;index"`
+	ConfigHash string       `gorm:"size:64;not null"`
+	BetAmount  float64      `gorm:"not null"`
+	WinAmount  float64      `gorm:"not null;default:0"`
+	Capped     bool         `gorm:"default:false"` // win was cut to the max win per bet
+	FreeSpins  int          `gorm:"not null;default:0"`
+	Outcome    string       `gorm:"type:mediumtext;not null"` // JSON with the screen and line wins of every spin
+	User       *User        `gorm:"belongsTo:User"`
+	Machine    *SlotMachine `gorm:"belongsTo:SlotMachine"`
+
+	ServerSeedID   *uint       `gorm:"null"`
+	ServerSeedHash string      `gorm:"size:64"`
+	ClientSeed     string      `gorm:"size:64"`
+	Nonce          uint64      `gorm:"not null;default:0"`
+	ServerSeed     *ServerSeed `gorm:"belongsTo:ServerSeed"`
+}
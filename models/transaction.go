@@ -8,7 +8,8 @@ type Transaction struct {
 	gorm.Model
 	UserID      uint    `gorm:"not null"`
 	GameID      *uint   `gorm:"null"`
-	Type        string  `gorm:"type:enum('bet', 'win', 'loss', 'topup', 'deduct', 'deposit', 'withdraw');not null"`
+	GameType    string  `gorm:"size:20;index"`
+	Type        string  `gorm:"type:enum('bet', 'win', 'loss', 'refund', 'topup', 'deduct', 'deposit', 'withdraw', 'jackpot', 'recovery');not null"`
 	Amount      float64 `gorm:"not null"`
 	Balance     float64 `gorm:"not null"`
 	Description string  `gorm:"not null"`
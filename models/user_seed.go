@@ -0,0 +1,14 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type UserSeed struct {
+	gorm.Model
+	UserID       uint        `gorm:"not null;unique"`
+	ClientSeed   string      `gorm:"size:64;not null"`
+	Nonce        uint64      `gorm:"not null;default:0"`
+	ServerSeedID uint        `gorm:"not null"`
+	ServerSeed   *ServerSeed `gorm:"belongsTo:ServerSeed"`
+}
@@ -23,7 +23,37 @@ func SetupAdminRoutes(router *gin.Engine) {
 		admin.GET("/users/:id/wallet/history", controllers.GetWalletHistory)
 
 		admin.GET("/games", controllers.GetAllGames)
+		admin.GET("/rounds", controllers.GetAllRounds)
+		admin.GET("/recovered-games", controllers.GetRecoveredGames)
 		admin.GET("/game-settings", controllers.GetAdminGameSettings)
 		admin.PUT("/game-settings", controllers.UpdateGameSettings)
+		admin.POST("/game-settings/simulate", controllers.SimulateGameSettings)
+		admin.GET("/game-settings/versions", controllers.GetGameSettingsVersions)
+		admin.GET("/game-settings/versions/diff", controllers.DiffGameSettingsVersions)
+		admin.POST("/game-settings/versions/:id/rollback", controllers.RollbackGameSettings)
+		admin.GET("/game-settings/scheduled", controllers.GetScheduledSettingsChanges)
+		admin.POST("/game-settings/scheduled/:id/cancel", controllers.CancelScheduledSettingsChange)
+		admin.GET("/maintenance", controllers.GetMaintenanceWindows)
+		admin.POST("/maintenance", controllers.CreateMaintenanceWindow)
+		admin.POST("/maintenance/:id/cancel", controllers.CancelMaintenanceWindow)
+		admin.GET("/dice-settings", controllers.GetAdminDiceSettings)
+		admin.PUT("/dice-settings", controllers.UpdateDiceSettings)
+		admin.GET("/mines-settings", controllers.GetAdminMinesSettings)
+		admin.PUT("/mines-settings", controllers.UpdateMinesSettings)
+		admin.GET("/plinko-tables", controllers.GetPlinkoTables)
+		admin.PUT("/plinko-tables", controllers.UpdatePlinkoTable)
+		admin.GET("/blackjack-settings", controllers.GetAdminBlackjackSettings)
+		admin.PUT("/blackjack-settings", controllers.UpdateBlackjackSettings)
+		admin.GET("/slots", controllers.GetSlotMachinesAdmin)
+		admin.POST("/slots", controllers.UploadSlotMachine)
+		admin.PUT("/slots/:id", controllers.UpdateSlotMachine)
+		admin.POST("/slots/:id/simulate", controllers.SimulateSlotMachine)
+		admin.POST("/slots/:id/activate", controllers.ActivateSlotMachine)
+		admin.POST("/slots/:id/deactivate", controllers.DeactivateSlotMachine)
+		admin.GET("/keno-settings", controllers.GetAdminKenoSettings)
+		admin.PUT("/keno-settings", controllers.UpdateKenoSettings)
+		admin.GET("/jackpot", controllers.GetAdminJackpot)
+		admin.PUT("/jackpot", controllers.UpdateJackpotSettings)
+		admin.POST("/jackpot/seed", controllers.SeedJackpot)
 	}
 }
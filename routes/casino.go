@@ -6,18 +6,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// SetupCasinoRoutes mounts the endpoints shared by every game. The crash
+// game's own endpoints live under the same path and are mounted with the
+// other games in SetupGameRoutes.
 func SetupCasinoRoutes(router *gin.Engine) {
 	casino := router.Group("/api/casino")
 	casino.Use(controllers.AuthMiddleware())
 	{
-		casino.POST("/start", controllers.StartGame)
-		casino.POST("/stop", controllers.StopGame)
-		casino.GET("/games", controllers.GetUserGames)
-		casino.GET("/settings", controllers.GetGameSettings)
+		casino.GET("/maintenance", controllers.GetMaintenanceStatus)
+		casino.GET("/ws", controllers.LiveFeed)
+		casino.GET("/game/:id/verify", controllers.VerifyGame)
 
-		casino.GET("/active-games", controllers.GetActiveGamesStatus)
-		casino.GET("/game/:id/crash-info", controllers.GetGameCrashInfo)
-
-		casino.GET("/game/:id", controllers.GetGameStatus)
+		casino.GET("/fairness", controllers.GetFairnessSeeds)
+		casino.PUT("/fairness/client-seed", controllers.UpdateClientSeed)
 	}
 }
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"casino_api_go/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupGameRoutes mounts every registered game provider under its base path,
+// with its play and history endpoints followed by its own extra routes.
+func SetupGameRoutes(router *gin.Engine) {
+	games := router.Group("/api/games")
+	games.Use(controllers.AuthMiddleware())
+	{
+		games.GET("", controllers.GetGameProviders)
+	}
+
+	for _, provider := range controllers.GameProviders() {
+		info := provider.Info()
+
+		group := router.Group(info.BasePath())
+		group.Use(controllers.AuthMiddleware())
+		{
+			group.POST(info.PlayPath, controllers.PlayGame(provider))
+			group.GET(info.HistoryPath, controllers.GameHistory(provider))
+			provider.Routes(group)
+		}
+	}
+}
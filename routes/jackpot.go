@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"casino_api_go/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupJackpotRoutes(router *gin.Engine) {
+	jackpot := router.Group("/api/jackpot")
+	{
+		jackpot.GET("", controllers.GetJackpot)
+		jackpot.GET("/wins", controllers.GetJackpotWins)
+	}
+}